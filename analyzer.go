@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// 在线密度分析仪的4~20mA信号：4mA对应量程下限，20mA对应上限，其间线性
+const (
+	signalMinMA = 4.0
+	signalMaxMA = 20.0
+
+	// NAMUR NE43：3.8~20.5mA为量程外的饱和信号，仍可换算；超出即为仪表故障或断线
+	signalFaultLowMA  = 3.8
+	signalFaultHighMA = 20.5
+)
+
+// parseMARange 解析-ma-range（密度下限:上限，g/cm³，如 1.3:1.6）
+func parseMARange(spec string) (lo, hi float64, err error) {
+	vals, err := parseColonFloats(spec, 2)
+	if err != nil {
+		return 0, 0, fmt.Errorf("-ma-range：%w", err)
+	}
+	if vals[0] <= 0 || vals[1] <= vals[0] {
+		return 0, 0, fmt.Errorf("-ma-range应为 下限:上限 且0 < 下限 < 上限，当前%s", spec)
+	}
+	return vals[0], vals[1], nil
+}
+
+// DensityFromCurrent 分析仪电流信号mA按量程[lo, hi]（g/cm³）线性换算为密度；
+// 3.8~4、20~20.5mA按同一直线外推，超出NAMUR NE43范围时返回错误
+func DensityFromCurrent(mA, lo, hi float64) (float64, error) {
+	if mA < signalFaultLowMA || mA > signalFaultHighMA {
+		return 0, fmt.Errorf("电流信号%.2fmA超出%g~%gmA，分析仪可能故障或断线", mA, signalFaultLowMA, signalFaultHighMA)
+	}
+	return lo + (mA-signalMinMA)/(signalMaxMA-signalMinMA)*(hi-lo), nil
+}
+
+// 执行-current-ma：换算出的密度代替-rho
+func densityFromSignalFlags(mA float64, spec string) (densityReading, error) {
+	if spec == "" {
+		return densityReading{}, fmt.Errorf("-current-ma需用-ma-range给出分析仪量程（如 1.3:1.6）")
+	}
+	lo, hi, err := parseMARange(spec)
+	if err != nil {
+		return densityReading{}, err
+	}
+	rho, err := DensityFromCurrent(mA, lo, hi)
+	if err != nil {
+		return densityReading{}, err
+	}
+	return densityReading{rho: rho}, nil
+}
@@ -0,0 +1,8 @@
+//go:build !asserts
+
+package main
+
+// 生产构建不含内部不变量检查：调用处以assertsEnabled常量包裹，编译期整体消除
+const assertsEnabled = false
+
+func assertf(cond bool, format string, args ...any) {}
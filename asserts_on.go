@@ -0,0 +1,15 @@
+//go:build asserts
+
+package main
+
+import "fmt"
+
+// 开发构建（go build -tags asserts）启用内部不变量检查，违反时带上下文panic
+const assertsEnabled = true
+
+// assertf cond不成立时panic
+func assertf(cond bool, format string, args ...any) {
+	if !cond {
+		panic("断言失败：" + fmt.Sprintf(format, args...))
+	}
+}
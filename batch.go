@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 批量模式可输出的列（默认全部，按此顺序）
+var batchColumns = []string{"T", "rho", "P", "C", "tw", "bpr", "tl", "error"}
+
+// 解析-columns：逗号分隔的列名，按给定顺序输出；空串表示全部列
+func parseColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return batchColumns, nil
+	}
+	var cols []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		valid := false
+		for _, c := range batchColumns {
+			if name == c {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("未知的列名%q（可选：%s）", name, strings.Join(batchColumns, ","))
+		}
+		cols = append(cols, name)
+	}
+	return cols, nil
+}
+
+// batchRow 批量模式中一行的输入与计算结果
+type batchRow struct {
+	line      int       // 所在源文件行号（从1起）
+	rawLine   string    // 源行内容，出错时随错误一并输出，便于定位修改
+	raw       [3]string // 输入原文（T、rho、P），原样回显
+	T, rho, P float64   // 解析后的输入（P为输入的表压或绝压）
+	res       Result
+	err       error
+}
+
+// 辅助：按列名取该行的输出文本；计算失败时结果列留空
+func (r batchRow) field(name string) string {
+	formatResult := func(v float64) string {
+		if r.err != nil {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	switch name {
+	case "T":
+		return r.raw[0]
+	case "rho":
+		return r.raw[1]
+	case "P":
+		return r.raw[2]
+	case "C":
+		return formatResult(r.res.C)
+	case "tw":
+		return formatResult(r.res.Tw)
+	case "bpr":
+		return formatResult(r.res.BPR)
+	case "tl":
+		return formatResult(r.res.Tl)
+	case "error":
+		if r.err != nil {
+			return fmt.Sprintf("第%d行（%s）：%v", r.line, r.rawLine, r.err)
+		}
+	}
+	return ""
+}
+
+// 批量输入格式
+const (
+	inputFormatCSV   = "csv"
+	inputFormatTSV   = "tsv"
+	inputFormatFixed = "fixed"
+)
+
+// parseFixedColumns 解析定宽列说明：逗号分隔的 起-止 字符位置（从1起，含两端），如 "1-6,8-13,15-20"
+func parseFixedColumns(spec string) ([][2]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("定宽输入需用-fixed-cols指定各列位置")
+	}
+	var cols [][2]int
+	for _, part := range strings.Split(spec, ",") {
+		var start, end int
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d-%d", &start, &end); err != nil {
+			return nil, fmt.Errorf("定宽列%q格式应为 起-止（如 1-6）", part)
+		}
+		if start < 1 || end < start {
+			return nil, fmt.Errorf("定宽列%q的位置无效", part)
+		}
+		cols = append(cols, [2]int{start, end})
+	}
+	if len(cols) < 2 || len(cols) > 3 {
+		return nil, fmt.Errorf("定宽列应为2列（T,rho）或3列（T,rho,P），实际%d列", len(cols))
+	}
+	return cols, nil
+}
+
+// inputColumnMap -input-columns：T、rho、P在源记录中的位置（从0起；P为-1表示未映射，压力取-P）
+type inputColumnMap struct {
+	T, rho, P int
+}
+
+// parseInputColumns 解析-input-columns：逗号分隔的 列名=位置（位置从1起），如 "T=3,rho=2,P=1"
+// T、rho必须给出，P可省略；源文件中未映射的列（样品编号、操作员等）忽略
+func parseInputColumns(spec string) (*inputColumnMap, error) {
+	pos := map[string]int{}
+	for _, part := range strings.Split(spec, ",") {
+		name, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.TrimSpace(name)
+		if !ok || (name != "T" && name != "rho" && name != "P") {
+			return nil, fmt.Errorf("输入列映射%q格式应为 列名=位置（列名可选 T、rho、P）", part)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("输入列映射%q的位置应为从1起的整数", part)
+		}
+		if _, dup := pos[name]; dup {
+			return nil, fmt.Errorf("输入列映射中%s重复", name)
+		}
+		pos[name] = n - 1
+	}
+	for _, name := range []string{"T", "rho"} {
+		if _, ok := pos[name]; !ok {
+			return nil, fmt.Errorf("输入列映射缺少%s", name)
+		}
+	}
+	m := &inputColumnMap{T: pos["T"], rho: pos["rho"], P: -1}
+	if p, ok := pos["P"]; ok {
+		m.P = p
+	}
+	return m, nil
+}
+
+// pick 按映射从源记录取出 T,rho,P（或未映射P、该列为空时的 T,rho）
+func (m *inputColumnMap) pick(rec []string) ([]string, error) {
+	field := func(name string, i int) (string, error) {
+		if i >= len(rec) {
+			return "", fmt.Errorf("%s映射到第%d列，但该行只有%d列", name, i+1, len(rec))
+		}
+		return rec[i], nil
+	}
+	T, err := field("T", m.T)
+	if err != nil {
+		return nil, err
+	}
+	rho, err := field("rho", m.rho)
+	if err != nil {
+		return nil, err
+	}
+	if m.P < 0 {
+		return []string{T, rho}, nil
+	}
+	P, err := field("P", m.P)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(P) == "" {
+		return []string{T, rho}, nil
+	}
+	return []string{T, rho, P}, nil
+}
+
+// recordReader 逐条读取批量输入记录，同时给出所在行号与该行原文；读完返回io.EOF
+type recordReader interface {
+	Read() (rec []string, line int, raw string, err error)
+}
+
+// csvRecordReader CSV/TSV读取；原文按分隔符重新拼接字段（引号等格式细节不保留）
+type csvRecordReader struct {
+	r *csv.Reader
+}
+
+func (c csvRecordReader) Read() ([]string, int, string, error) {
+	rec, err := c.r.Read()
+	if err != nil {
+		return nil, 0, "", err
+	}
+	line, _ := c.r.FieldPos(0)
+	return rec, line, strings.Join(rec, string(c.r.Comma)), nil
+}
+
+// fixedReader 按字符位置切分定宽行；空行与#开头的行跳过，行长不足时缺失字段为空
+type fixedReader struct {
+	sc   *bufio.Scanner
+	cols [][2]int
+	line int
+}
+
+func (r *fixedReader) Read() ([]string, int, string, error) {
+	for r.sc.Scan() {
+		r.line++
+		line := []rune(r.sc.Text())
+		if t := strings.TrimSpace(string(line)); t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		rec := make([]string, len(r.cols))
+		for i, c := range r.cols {
+			if c[0] > len(line) {
+				continue
+			}
+			rec[i] = strings.TrimSpace(string(line[c[0]-1 : min(c[1], len(line))]))
+		}
+		// 末尾压力列为空时按两列处理（压力取-P）
+		if len(rec) == 3 && rec[2] == "" {
+			rec = rec[:2]
+		}
+		return rec, r.line, strings.TrimRight(string(line), " \t"), nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return nil, r.line, "", err
+	}
+	return nil, r.line, "", io.EOF
+}
+
+// 辅助：按输入格式创建记录读取器
+func newRecordReader(in io.Reader, cfg batchConfig) recordReader {
+	if cfg.format == inputFormatFixed {
+		return &fixedReader{sc: bufio.NewScanner(in), cols: cfg.fixedColumns}
+	}
+	r := csv.NewReader(in)
+	if cfg.format == inputFormatTSV {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	return csvRecordReader{r}
+}
+
+// batchConfig 批量模式的设置
+type batchConfig struct {
+	columns      []string
+	format       string          // 输入格式（csv|tsv|fixed）
+	fixedColumns [][2]int        // 定宽格式各列的字符位置
+	inputColumns *inputColumnMap // 源记录中各输入所在的列（nil表示按 T,rho,P 顺序）
+	pressureType string          // 输入压力类型（absolute|gauge）
+	atm          float64         // 当地大气压（kPa）
+
+	defaultP    float64 // 只有 T,rho 两列的行使用的压力（-P）
+	hasDefaultP bool
+}
+
+// runBatch 批量计算：逐行读取 T,rho,P 或 T,rho（压力取-P），首行非数字时视为表头跳过，输出选定列的CSV
+// 单行计算失败不中断，错误写入error列
+func runBatch(in io.Reader, out io.Writer, cfg batchConfig) error {
+	r := newRecordReader(in, cfg)
+
+	w := newRecordWriter(out)
+	if err := w.Write(cfg.columns); err != nil {
+		return err
+	}
+
+	first := true
+	for {
+		rec, line, raw, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		isFirst := first
+		first = false
+
+		var row batchRow
+		ok := true
+		if cfg.inputColumns != nil {
+			rec, err = cfg.inputColumns.pick(rec)
+		}
+		if err != nil {
+			row.err = err
+		} else {
+			row, ok = parseBatchRecord(rec, cfg)
+		}
+		if !ok && isFirst {
+			continue // 表头
+		}
+		row.line, row.rawLine = line, raw
+		if row.err == nil {
+			row.res, row.err = calculateBatchRow(row, cfg)
+		}
+
+		fields := make([]string, len(cfg.columns))
+		for i, name := range cfg.columns {
+			fields[i] = row.field(name)
+		}
+		if err := w.Write(fields); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// 辅助：解析一行 T,rho,P（或 T,rho，压力取默认值）；ok为假表示该行含非数字字段
+// 先逐列判断是否为数字、再按列数规则检查：只有两列的表头（如 T,rho）在未给-P时也应识别为表头，而不是报列数错误
+func parseBatchRecord(rec []string, cfg batchConfig) (row batchRow, ok bool) {
+	var vals [3]float64
+	for i := 0; i < len(rec) && i < 3; i++ {
+		row.raw[i] = strings.TrimSpace(rec[i])
+		num, err := normalizeNumber(row.raw[i])
+		if err != nil {
+			row.err = fmt.Errorf("第%d列：%w", i+1, err) // 小数点有歧义：是数字，不当作表头
+			return row, true
+		}
+		v, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			row.err = fmt.Errorf("第%d列%q不是数字", i+1, row.raw[i])
+			return row, false
+		}
+		vals[i] = v
+	}
+	switch {
+	case len(rec) == 2 && cfg.hasDefaultP:
+		row.raw[2] = strconv.FormatFloat(cfg.defaultP, 'f', -1, 64)
+		vals[2] = cfg.defaultP
+	case len(rec) == 2:
+		row.err = fmt.Errorf("只有T,rho两列，且未用-P指定默认压力")
+		return row, true
+	case len(rec) != 3:
+		row.err = fmt.Errorf("应为3列（T,rho,P）或2列（T,rho），实际%d列", len(rec))
+		return row, true
+	}
+	row.T, row.rho, row.P = vals[0], vals[1], vals[2]
+	return row, true
+}
+
+// 辅助：换算压力后执行计算
+func calculateBatchRow(row batchRow, cfg batchConfig) (Result, error) {
+	P, err := toAbsolutePressure(row.P, cfg.pressureType, cfg.atm)
+	if err != nil {
+		return Result{}, err
+	}
+	return Calculate(row.T, row.rho, P)
+}
+
+// jsonRequest -input-json数组中的一项；P缺省时取-P
+type jsonRequest struct {
+	T   *float64 `json:"T"`
+	Rho *float64 `json:"rho"`
+	P   *float64 `json:"P"`
+}
+
+// runJSONBatch 读取请求对象的JSON数组，按相同顺序输出结果的JSON数组；单项失败时该位置为{"error": ...}
+func runJSONBatch(in io.Reader, out io.Writer, cfg batchConfig) error {
+	var reqs []jsonRequest
+	dec := json.NewDecoder(in)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&reqs); err != nil {
+		return fmt.Errorf("输入应为请求对象的JSON数组（如 [{\"T\":60,\"rho\":1.45,\"P\":20}]）：%w", err)
+	}
+
+	results := make([]any, len(reqs))
+	for i, req := range reqs {
+		res, err := calculateJSONRequest(req, cfg)
+		if err != nil {
+			results[i] = jsonError{Error: fmt.Sprintf("第%d项：%v", i+1, err)}
+			continue
+		}
+		results[i] = res
+	}
+	return json.NewEncoder(out).Encode(results)
+}
+
+// 辅助：校验必填字段后计算一项请求
+func calculateJSONRequest(req jsonRequest, cfg batchConfig) (Result, error) {
+	if req.T == nil || req.Rho == nil {
+		return Result{}, fmt.Errorf("缺少T或rho")
+	}
+	row := batchRow{T: *req.T, rho: *req.Rho, P: cfg.defaultP}
+	switch {
+	case req.P != nil:
+		row.P = *req.P
+	case !cfg.hasDefaultP:
+		return Result{}, fmt.Errorf("缺少P，且未用-P指定默认压力")
+	}
+	res, err := calculateBatchRow(row, cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	if cfg.pressureType == pressureGauge {
+		res.PGauge = row.P
+	}
+	res.addWarning(pressurePrecisionWarning(row.P))
+	return res, nil
+}
+
+// 执行-batch：path为 - 时读标准输入
+func runBatchFile(path string, cfg batchConfig) error {
+	return withInputFile(path, func(in io.Reader) error { return runBatch(in, os.Stdout, cfg) })
+}
+
+// 执行-input-json：读取JSON数组文件（- 表示标准输入）
+func runJSONBatchFile(path string, cfg batchConfig) error {
+	return withInputFile(path, func(in io.Reader) error { return runJSONBatch(in, os.Stdout, cfg) })
+}
+
+// 辅助：打开输入文件（- 表示标准输入）并交给fn处理
+func withInputFile(path string, fn func(io.Reader) error) error {
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	return fn(in)
+}
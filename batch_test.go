@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// 只有T,rho两列的表头：未给-P时也按表头跳过，数据行各自报缺少默认压力；给出-P时按默认压力计算
+func TestRunBatchTwoColumnHeader(t *testing.T) {
+	const in = "T,rho\n60,1.5\n70,1.48\n"
+	cfg := batchConfig{columns: []string{"T", "rho", "P", "tl", "error"}, format: inputFormatCSV, pressureType: pressureAbsolute}
+
+	var out bytes.Buffer
+	if err := runBatch(strings.NewReader(in), &out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || strings.Contains(out.String(), "第1行") {
+		t.Fatalf("未给-P：输出%q，表头不应成为错误行", out.String())
+	}
+	for _, l := range lines[1:] {
+		if !strings.Contains(l, "未用-P指定默认压力") {
+			t.Errorf("未给-P：数据行%q应报缺少默认压力", l)
+		}
+	}
+
+	cfg.defaultP, cfg.hasDefaultP = 20, true
+	out.Reset()
+	if err := runBatch(strings.NewReader(in), &out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := "T,rho,P,tl,error\n60,1.5,20,72.0,\n"
+	if !strings.HasPrefix(out.String(), want) || strings.Count(out.String(), "\n") != 3 {
+		t.Errorf("-P 20：输出%q，应以%q开头且共3行", out.String(), want)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import "fmt"
+
+// BlendStreams 两股已知浓度（%）与体积（m³）的料液混合后的结果：浓度、温度T下的密度及压力P（kPa，绝压）下的沸点
+// 两股料液均按温度T下的密度将体积换算为质量，对溶质做物料衡算：C = (m1·C1 + m2·C2) / (m1 + m2)；
+// 再由DensityFor求混合液密度，沸点按已知浓度的标准流程计算。混合时的体积变化与热效应不计
+func BlendStreams(C1, vol1, C2, vol2, T, P float64) (Result, error) {
+	if vol1 < 0 || vol2 < 0 || vol1+vol2 <= 0 {
+		return Result{}, fmt.Errorf("两股料液体积须非负且不全为0，当前%.2fm³、%.2fm³", vol1, vol2)
+	}
+	var mass, salt float64
+	for i, s := range [][2]float64{{C1, vol1}, {C2, vol2}} {
+		rho, err := DensityFor(T, s[0])
+		if err != nil {
+			return Result{}, fmt.Errorf("第%d股料液（%.1f%%）：%w", i+1, s[0], err)
+		}
+		m := s[1] * rho * 1000 // g/cm³ → kg/m³
+		mass += m
+		salt += m * s[0] / 100
+	}
+	C := salt / mass * 100
+
+	rho, err := DensityFor(T, C)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := CalculateFromConcentration(C, P)
+	if err != nil {
+		return Result{}, err
+	}
+	res.T, res.Rho = T, roundHalfUp(rho, 3)
+	res.C = roundHalfUp(C, 1)
+	return res, nil
+}
+
+// 执行-blend：格式 浓度1:体积1:浓度2:体积2，温度取-T，压力取-P
+func runBlend(spec string, given map[string]bool, T, P float64, pressureType string, atm float64) error {
+	if !given["T"] || !given["P"] {
+		return fmt.Errorf("混合计算需用-T和-P指定混合温度与工艺压力")
+	}
+	vals, err := parseColonFloats(spec, 4)
+	if err != nil {
+		return err
+	}
+	PAbs, err := toAbsolutePressure(P, pressureType, atm)
+	if err != nil {
+		return err
+	}
+	res, err := BlendStreams(vals[0], vals[1], vals[2], vals[3], T, PAbs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("料液1：%.1f%%，%.2fm³；料液2：%.1f%%，%.2fm³；混合温度%.1f℃，工艺压力：%s\n",
+		vals[0], vals[1], vals[2], vals[3], T, formatPressure(PAbs, pressureType, atm))
+	fmt.Printf("混合液浓度：%.1f%%\n", res.C)
+	fmt.Printf("混合液密度：%.3f g/cm³\n", res.Rho)
+	fmt.Printf("纯水沸点：%.1f℃，BPR：%.1f℃\n", res.Tw, res.BPR)
+	fmt.Printf("混合液沸点：%.1f℃\n", res.Tl)
+	for _, w := range res.Warnings {
+		fmt.Printf("警告：%s\n", w)
+	}
+	return nil
+}
+
+// 执行-evaporate：输出蒸浓所需蒸发水量
+func runEvaporate(spec string) error {
+	vals, err := parseColonFloats(spec, 3)
+	if err != nil {
+		return err
+	}
+	startC, targetC, volume := vals[0], vals[1], vals[2]
+	water, err := WaterToEvaporate(startC, targetC, volume)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("起始浓度%.1f%%、体积%.2fm³（按%.0f℃密度换算），蒸浓至%.1f%%\n", startC, volume, volumeReferenceTemp, targetC)
+	fmt.Printf("需蒸发水量：%.0f kg（%.2f t）\n", water, water/1000)
+	return nil
+}
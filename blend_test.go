@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// 混合按20℃密度换算质量后对溶质衡算：48%×1m³（1.540）与52%×3m³（1.599）
+// → (1540×0.48 + 4797×0.52) / 6337 = 51.028%，沸点按该浓度的标准流程计算
+func TestBlendStreams(t *testing.T) {
+	const wantC = (1540*0.48 + 4797*0.52) / 6337 * 100
+	res, err := BlendStreams(48, 1, 52, 3, 20, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := CalculateFromConcentration(wantC, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.C != 51.0 || res.Rho != 1.584 || res.Tl != want.Tl || res.T != 20 {
+		t.Errorf("C=%v rho=%v tl=%v T=%v，应为51.0%%、1.584、%v、20℃", res.C, res.Rho, res.Tl, res.T, want.Tl)
+	}
+
+	// 只有一股料液时即为该料液本身
+	res, err = BlendStreams(50, 2, 48, 0, 20, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.C != 50 || math.Abs(res.Rho-1.569) > 1e-9 {
+		t.Errorf("单股50%%：C=%v rho=%v，应为50%%、1.569", res.C, res.Rho)
+	}
+
+	if _, err := BlendStreams(48, 0, 52, 0, 20, 20); err == nil {
+		t.Error("两股体积均为0：应返回错误")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"container/list"
+	"math"
+	"sync"
+)
+
+// 服务模式结果缓存的默认条目数
+const defaultCacheSize = 256
+
+// cacheKey 缓存键：输入按1e-6取整，消除解析与表压换算带来的浮点表示差异
+type cacheKey struct {
+	T, rho, P float64
+}
+
+func newCacheKey(T, rho, P float64) cacheKey {
+	r := func(v float64) float64 { return math.Round(v*1e6) / 1e6 }
+	return cacheKey{r(T), r(rho), r(P)}
+}
+
+type cacheEntry struct {
+	key cacheKey
+	res Result
+}
+
+// resultCache 计算结果的LRU缓存（计算是确定性的，相同输入可直接复用），并发安全
+// size不大于0时不缓存
+type resultCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List // 最近使用的在前
+	items map[cacheKey]*list.Element
+
+	hits, misses uint64
+}
+
+func newResultCache(size int) *resultCache {
+	return &resultCache{size: size, ll: list.New(), items: map[cacheKey]*list.Element{}}
+}
+
+// get 命中时返回缓存结果并计数
+func (c *resultCache) get(k cacheKey) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[k]; ok {
+		c.ll.MoveToFront(e)
+		c.hits++
+		return e.Value.(*cacheEntry).res, true
+	}
+	c.misses++
+	return Result{}, false
+}
+
+// put 写入结果，超出容量时淘汰最久未用的条目
+func (c *resultCache) put(k cacheKey, res Result) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[k]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*cacheEntry).res = res
+		return
+	}
+	c.items[k] = c.ll.PushFront(&cacheEntry{k, res})
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// stats 命中数、未命中数与当前条目数
+func (c *resultCache) stats() (hits, misses uint64, entries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.ll.Len()
+}
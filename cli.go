@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/pprof"
+)
+
+// 按日志级别（debug|info|warn|error）设置slog，日志写stderr，stdout只留结果
+func setupLogging(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("未知的日志级别%q（可选 debug|info|warn|error）", level)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l})))
+	return nil
+}
+
+// 退出前需执行的收尾（如写完CPU profile）：main正常返回时由defer执行，提前退出统一经exit
+var atExit []func()
+
+func runAtExit() {
+	for _, f := range atExit {
+		f()
+	}
+	atExit = nil
+}
+
+func exit(code int) {
+	runAtExit()
+	os.Exit(code)
+}
+
+// exitOnError err非nil时写到stderr（形如"prefix：err"）并以状态code退出：2为参数有误，1为运行失败。
+// 各运行模式与参数校验统一经此报错，stdout只留结果；单点计算按输出模式另行报错（见singleConfig.fail）
+func exitOnError(code int, prefix string, err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s：%v\n", prefix, err)
+	exit(code)
+}
+
+// 开始CPU profile，写入path，退出时结束
+func startCPUProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	atExit = append(atExit, func() {
+		pprof.StopCPUProfile()
+		f.Close()
+	})
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import "math"
+
+// 可信度评分（-confidence）：把各项质量信号汇总为0~100分，便于筛出需要复测的读数。
+// 满分100，按下表扣分，最低0分：
+//
+//	密度超出表范围、浓度取端点值（任一次限幅）      −40
+//	浓度不确定度（密度计精度×|dC/drho|）            每0.1% −2，最多−30（密度平缓段按−30）
+//	自洽残差|实测密度 − 按浓度回算|                每0.001 g/cm³ −10，最多−30
+//	浓度超出BPR关联适用区间（外推）                 每0.1% −2，最多−30
+//	压力低于常规下限8kPa（深度真空）                每1kPa −5，最多−20
+//	压力修正系数K限幅生效                          −10
+//
+// 各项的扣分尺度按对溶液沸点的影响取齐：浓度偏差0.1%约使沸点偏0.1℃；
+// 已知浓度计算（-C）时没有密度，只计外推、压力与K限幅三项
+const (
+	confidenceClampPenalty = 40.0
+
+	confidenceCTolPerPct = 20.0 // 每1%浓度不确定度
+	confidenceCTolMax    = 30.0
+
+	confidenceResidualPerGCm3 = 10000.0 // 每1 g/cm³自洽残差（即每0.001扣10）
+	confidenceResidualMax     = 30.0
+
+	confidenceExtrapPerPct = 20.0 // 每1%超出BPR适用区间
+	confidenceExtrapMax    = 30.0
+
+	confidenceVacuumPerKPa = 5.0
+	confidenceVacuumMax    = 20.0
+
+	confidenceKClampPenalty = 10.0
+)
+
+// confidenceScore 按上表汇总一次计算的可信度（0~100，取整）
+func confidenceScore(s calcSteps, P float64) int {
+	penalty := 0.0
+	if len(s.clamps) > 0 {
+		penalty += confidenceClampPenalty
+	}
+	if math.IsInf(s.sensitivity, 0) || math.IsNaN(s.sensitivity) {
+		penalty += confidenceCTolMax
+	} else {
+		penalty += min(math.Abs(s.sensitivity)*opts.densityTol*confidenceCTolPerPct, confidenceCTolMax)
+	}
+	penalty += min(math.Abs(s.rhoResidual)*confidenceResidualPerGCm3, confidenceResidualMax)
+
+	if dist := max(bprMinC-s.C, s.C-bprMaxC); dist > 0 {
+		penalty += min(dist*confidenceExtrapPerPct, confidenceExtrapMax)
+	}
+	if P < minProcessPressure {
+		penalty += min((minProcessPressure-P)*confidenceVacuumPerKPa, confidenceVacuumMax)
+	}
+	if _, raw := pressureCorrection(s.tw); raw != s.K {
+		penalty += confidenceKClampPenalty
+	}
+	return int(math.Round(max(100-penalty, 0)))
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// fileConfig -config指定的JSON配置文件
+type fileConfig struct {
+	Calibration []calibrationPoint      `json:"calibration"`           // 现场实测BPR校正点，空表示不校正
+	Density     map[string][][2]float64 `json:"density,omitempty"`     // 并入内置密度表的数据（格式同-seed-tables），空表示不改动
+	KTable      [][2]float64            `json:"k_table,omitempty"`     // 实测压力修正曲线 [[tw, K], ...]（tw升序），空表示用默认曲线
+	VaporTable  [][2]float64            `json:"vapor_table,omitempty"` // 纯水饱和蒸气压表 [[P kPa, 沸点℃], ...]（两列均升序），空表示沿用物性数据的表
+}
+
+// calibrationPoint 一个可信的现场实测点：浓度C（%）的溶液在绝压P（kPa）下实测BPR（℃）
+type calibrationPoint struct {
+	C   float64 `json:"C"`
+	P   float64 `json:"P"`
+	BPR float64 `json:"bpr"`
+}
+
+// loadConfig 读取JSON配置文件；含未知字段时报错，避免拼写错误的配置被静默忽略
+func loadConfig(path string) (fileConfig, error) {
+	var cfg fileConfig
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("配置文件%s格式错误：%w", path, err)
+	}
+	return cfg, nil
+}
+
+// bprCorrection 对模型BPR的线性校正：校正后BPR = scale × 模型BPR + offset
+type bprCorrection struct {
+	scale, offset float64
+	points        int // 拟合所用的校正点数（0表示未校正）
+}
+
+// 当前生效的BPR校正（启动时由配置设置，计算期间只读）
+var bprCalibration = bprCorrection{scale: 1}
+
+// 辅助：应用BPR校正
+func (c bprCorrection) apply(bpr float64) float64 {
+	return c.scale*bpr + c.offset
+}
+
+// fitBPRCorrection 按最小二乘拟合校正：1个点（或各点模型BPR相同）时只平移，2个点以上同时拟合比例与平移
+// 模型BPR按当前物性数据、未校正状态计算
+func fitBPRCorrection(points []calibrationPoint) (bprCorrection, error) {
+	if len(points) == 0 {
+		return bprCorrection{scale: 1}, nil
+	}
+	model := make([]float64, len(points))
+	var sumX, sumY float64
+	for i, p := range points {
+		tw, err := getPureWaterBoilingPoint(p.P)
+		if err != nil {
+			return bprCorrection{}, fmt.Errorf("校正点%d（C=%g%%，P=%gkPa）：%w", i+1, p.C, p.P, err)
+		}
+		_, _, bpr, _, err := boilingPointForConcentration(p.C, tw)
+		if err != nil {
+			return bprCorrection{}, fmt.Errorf("校正点%d（C=%g%%，P=%gkPa）：%w", i+1, p.C, p.P, err)
+		}
+		model[i] = bpr
+		sumX += bpr
+		sumY += p.BPR
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy float64
+	for i, p := range points {
+		sxx += (model[i] - meanX) * (model[i] - meanX)
+		sxy += (model[i] - meanX) * (p.BPR - meanY)
+	}
+	if sxx < 1e-9 {
+		return bprCorrection{scale: 1, offset: meanY - meanX, points: len(points)}, nil
+	}
+	scale := sxy / sxx
+	if scale <= 0 || math.IsNaN(scale) {
+		return bprCorrection{}, fmt.Errorf("校正点拟合的比例系数%.3f不为正，实测BPR与模型趋势相反，请核对校正数据", scale)
+	}
+	return bprCorrection{scale: scale, offset: meanY - scale*meanX, points: len(points)}, nil
+}
+
+// applyConfig 读取配置文件并使之生效（须在选定物性数据之后调用）：先并入密度数据、替换蒸气压表，再按合并后的表拟合BPR校正
+func applyConfig(path string) error {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Density) > 0 {
+		extra, err := parseDensityRows(cfg.Density, path)
+		if err != nil {
+			return err
+		}
+		merged, _ := mergeDensityRows(densityRows(), extra)
+		if err := validateDensityTable(merged); err != nil {
+			return fmt.Errorf("并入%s的密度数据后：%w", path, err)
+		}
+		setDensityTable(merged)
+	}
+	if len(cfg.VaporTable) > 0 {
+		vapor := make([]vaporPressurePoint, len(cfg.VaporTable))
+		for i, pt := range cfg.VaporTable {
+			vapor[i] = vaporPressurePoint{Pressure_kPa: pt[0], Temp_C: pt[1]}
+		}
+		if err := validateVaporPressureTable(vapor); err != nil {
+			return fmt.Errorf("%s的vapor_table：%w", path, err)
+		}
+		VaporPressureTable = vapor
+	}
+	kCorrectionTable = defaultKCorrectionTable
+	if len(cfg.KTable) > 0 {
+		if err := validateKTable(cfg.KTable); err != nil {
+			return fmt.Errorf("%s的k_table：%w", path, err)
+		}
+		kCorrectionTable = cfg.KTable
+	}
+	bprCalibration = bprCorrection{scale: 1}
+	corr, err := fitBPRCorrection(cfg.Calibration)
+	if err != nil {
+		return err
+	}
+	bprCalibration = corr
+	return nil
+}
+
+// loadSeedTables 读取-seed-tables补充的密度数据：{"温度": [[浓度%, 密度g/cm³], ...], ...}
+func loadSeedTables(path string) (map[float64][][2]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string][][2]float64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("补充密度表%s格式错误（应为 {\"温度\": [[浓度, 密度], ...]}）：%w", path, err)
+	}
+	return parseDensityRows(raw, path)
+}
+
+// 辅助：将以温度字符串为键的密度数据转为以温度为键（path仅用于错误信息）
+func parseDensityRows(raw map[string][][2]float64, path string) (map[float64][][2]float64, error) {
+	rows := make(map[float64][][2]float64, len(raw))
+	for key, pairs := range raw {
+		T, err := strconv.ParseFloat(key, 64)
+		if err != nil {
+			return nil, fmt.Errorf("补充密度表%s：温度键%q不是数字", path, key)
+		}
+		rows[T] = pairs
+	}
+	return rows, nil
+}
+
+// mergeDensityRows 将补充数据并入密度表（返回新表，不改动base）：新温度行直接加入，
+// 已有行按浓度合并后重新排序；同一温度、同一浓度的点以补充数据为准，并在warnings中说明
+func mergeDensityRows(base, extra map[float64][][2]float64) (merged map[float64][][2]float64, warnings []string) {
+	merged = make(map[float64][][2]float64, len(base)+len(extra))
+	for T, pairs := range base {
+		merged[T] = append([][2]float64(nil), pairs...)
+	}
+
+	temps := make([]float64, 0, len(extra))
+	for T := range extra {
+		temps = append(temps, T)
+	}
+	sort.Float64s(temps)
+	for _, T := range temps {
+		row := merged[T]
+		for _, p := range extra[T] {
+			replaced := false
+			for i := range row {
+				if row[i][0] == p[0] {
+					if row[i][1] != p[1] {
+						warnings = append(warnings, fmt.Sprintf("密度表%g℃行浓度%g%%：内置%.3f被补充数据%.3f覆盖", T, p[0], row[i][1], p[1]))
+					}
+					row[i][1] = p[1]
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				row = append(row, p)
+			}
+		}
+		sort.Slice(row, func(i, j int) bool { return row[i][0] < row[j][0] })
+		merged[T] = row
+	}
+	return merged, warnings
+}
+
+// applySeedTables 读取补充密度数据并入当前密度表，合并后重新校验（须在选定物性数据之后调用）
+func applySeedTables(path string) ([]string, error) {
+	extra, err := loadSeedTables(path)
+	if err != nil {
+		return nil, err
+	}
+	merged, warnings := mergeDensityRows(densityRows(), extra)
+	if err := validateDensityTable(merged); err != nil {
+		return nil, fmt.Errorf("并入%s后：%w", path, err)
+	}
+	setDensityTable(merged)
+	return warnings, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// -config：密度数据并入内置表、k_table替换K曲线、按校正点拟合BPR，结束后恢复内置数据
+func TestApplyConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "site.json")
+	const cfg = `{
+	"calibration": [{"C": 50, "P": 20, "bpr": 9.0}],
+	"density": {
+		"60": [[52.5, 1.5495]],
+		"70": [[0, 0.996], [45, 1.400], [50, 1.472], [52, 1.501]]
+	},
+	"k_table": [[40, 1.10], [80, 1.02]]
+}`
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := withConfig(path, func() error {
+		// 已有行按浓度插入，新温度行直接加入
+		row60 := densityRows()[60]
+		if len(row60) != 11 || row60[9] != [2]float64{52.5, 1.5495} {
+			t.Errorf("60℃行合并结果%v，应在52%%与53%%之间插入(52.5, 1.5495)", row60)
+		}
+		want70 := [][2]float64{{0, 0.996}, {45, 1.400}, {50, 1.472}, {52, 1.501}}
+		if !slices.Equal(densityRows()[70], want70) {
+			t.Errorf("70℃行%v，应为%v", densityRows()[70], want70)
+		}
+		if !slices.Contains(tables().sortedTemps, 70) {
+			t.Errorf("预计算的温度%v未含新加入的70℃", tables().sortedTemps)
+		}
+		if !slices.Equal(kCorrectionTable, [][2]float64{{40, 1.10}, {80, 1.02}}) {
+			t.Errorf("K曲线%v未替换为k_table", kCorrectionTable)
+		}
+
+		// 单个校正点只平移：偏移量 = 实测BPR − 模型BPR（按合并后的物性与k_table，未校正）
+		tw, err := getPureWaterBoilingPoint(20)
+		if err != nil {
+			return err
+		}
+		fitted := bprCalibration
+		bprCalibration = bprCorrection{scale: 1}
+		_, _, model, _, err := boilingPointForConcentration(50, tw)
+		bprCalibration = fitted
+		if err != nil {
+			return err
+		}
+		offset := 9.0 - model
+		if bprCalibration.points != 1 || bprCalibration.scale != 1 || math.Abs(bprCalibration.offset-offset) > 1e-9 {
+			t.Errorf("BPR校正%+v，应为scale=1、offset=%v", bprCalibration, offset)
+		}
+
+		// 校正点处的计算结果复现实测BPR
+		res, err := CalculateFromConcentration(50, 20)
+		if err != nil {
+			return err
+		}
+		if res.BPR != 9.0 {
+			t.Errorf("校正点C=50%%、P=20kPa：BPR=%v，应为实测值9.0", res.BPR)
+		}
+
+		// 新加入的70℃行参与反查：表点处得表中浓度
+		res, err = Calculate(70, 1.472, 20)
+		if err != nil {
+			return err
+		}
+		if res.C != 50 {
+			t.Errorf("T=70 rho=1.472：C=%v，应为70℃行的表点50", res.C)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := densityRows()[70]; ok {
+		t.Error("withConfig结束后未恢复内置密度表")
+	}
+	if !slices.Equal(kCorrectionTable, defaultKCorrectionTable) || bprCalibration.points != 0 {
+		t.Error("withConfig结束后未恢复K曲线与BPR校正")
+	}
+}
+
+// 配置的vapor_table：升序时替换蒸气压表，乱序时报错并指明数据点，结束后恢复内置表
+func TestApplyConfigVaporTable(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(good, []byte(`{"vapor_table": [[10, 45.8], [20, 60.1], [30, 69.1]]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte(`{"vapor_table": [[10, 45.8], [30, 69.1], [20, 60.1]]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := withConfig(good, func() error {
+		tw, err := getPureWaterBoilingPoint(20)
+		if err != nil {
+			return err
+		}
+		if tw != 60.1 {
+			t.Errorf("P=20kPa：tw=%v，应为vapor_table中的60.1", tw)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = withConfig(bad, func() error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "vapor_table：蒸气压表压力未严格升序：第3点20kPa不大于第2点30kPa") {
+		t.Errorf("乱序的vapor_table：%v，应指明第3点", err)
+	}
+	if !slices.Equal(VaporPressureTable, defaultVaporPressureTable) {
+		t.Error("withConfig结束后未恢复内置蒸气压表")
+	}
+}
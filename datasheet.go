@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeDatasheet 产品数据表用的沸点块（-datasheet）：工艺压力下的溶液沸点与101.325kPa下的参考沸点并列
+// 参考沸点即res.TlAtm（常压BPR路径，不经压力修正）
+func writeDatasheet(w io.Writer, res Result, pressure string) error {
+	density := "—（按已知浓度计算）"
+	if res.Rho > 0 {
+		density = fmt.Sprintf("%.3f g/cm³（%.1f ℃下实测）", res.Rho, res.T)
+	}
+	conc := fmt.Sprintf("%.1f %%（七水合硫酸钴计）", res.C)
+	if res.CAnhydrous > 0 {
+		conc += fmt.Sprintf("，%.1f %%（无水CoSO4计）", res.CAnhydrous)
+	}
+	lines := []string{
+		"=== 产品数据表：沸点 ===",
+		"浓度：                    " + conc,
+		"密度依据：                " + density,
+		"工艺压力：                " + pressure,
+	}
+	if res.T > 0 {
+		lines = append(lines, fmt.Sprintf("工艺温度：                %.1f ℃", res.T))
+	}
+	lines = append(lines,
+		fmt.Sprintf("溶液沸点（工艺压力下）：  %.1f ℃", res.Tl),
+		fmt.Sprintf("溶液沸点（101.325 kPa）： %.1f ℃", res.TlAtm),
+	)
+	for _, warning := range res.Warnings {
+		lines = append(lines, "注："+warning)
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
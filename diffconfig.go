@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 比较两版配置所用的标准网格：温度20~100℃每10℃，浓度45~52%每0.5%，常用压力8/15/20/28kPa
+// 样品密度按旧版表由(T, C)生成并取3位小数，两版表对同一组(T, rho, P)计算
+func diffConfigGrid() (temps, concs, pressures []float64) {
+	for T := 20.0; T <= 100; T += 10 {
+		temps = append(temps, T)
+	}
+	for i := 0; i <= 14; i++ {
+		concs = append(concs, 45+0.5*float64(i))
+	}
+	return temps, concs, []float64{8, 15, 20, 28}
+}
+
+// 辅助：在基准数据上应用path配置后执行fn，结束后恢复基准密度表、蒸气压表、BPR校正与K曲线
+func withConfig(path string, fn func() error) error {
+	savedTable, savedVapor, savedCal, savedK := densityRows(), VaporPressureTable, bprCalibration, kCorrectionTable
+	defer func() {
+		setDensityTable(savedTable)
+		VaporPressureTable, bprCalibration, kCorrectionTable = savedVapor, savedCal, savedK
+	}()
+	if err := applyConfig(path); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// 辅助：以path配置计算一点；配置本身的错误已在开始时检查
+func calculateWithConfig(path string, T, rho, P float64) (res Result, err error) {
+	withConfig(path, func() error {
+		res, err = Calculate(T, rho, P)
+		return nil
+	})
+	return res, err
+}
+
+// runDiffConfig 对比两版配置文件（"旧,新"）在标准网格上的计算结果：浓度与溶液沸点变化写成CSV，
+// 汇总（变化点数、最大变化及位置）写到errOut；任一版计算失败的点在error列注明
+func runDiffConfig(spec string, out, errOut io.Writer) error {
+	paths := strings.Split(spec, ",")
+	if len(paths) != 2 || paths[0] == "" || paths[1] == "" {
+		return fmt.Errorf("-diff-config格式应为 旧配置.json,新配置.json")
+	}
+	// 配置本身有误时直接报错，不逐点重复
+	for _, path := range paths {
+		if err := withConfig(path, func() error { return nil }); err != nil {
+			return err
+		}
+	}
+
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"T", "rho", "P", "C_old", "C_new", "dC", "tl_old", "tl_new", "dtl", "error"}); err != nil {
+		return err
+	}
+	f1 := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	var points, changed int
+	var maxDC, maxDtl float64
+	var maxDCAt, maxDtlAt string
+	temps, concs, pressures := diffConfigGrid()
+	for _, T := range temps {
+		for _, C := range concs {
+			var rho float64
+			err := withConfig(paths[0], func() (err error) {
+				rho, err = DensityFor(T, C)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("按%s生成T=%g℃、C=%g%%的样品密度：%w", paths[0], T, C, err)
+			}
+			rho = roundHalfUp(rho, 3)
+			for _, P := range pressures {
+				points++
+				rec := []string{f1(T), strconv.FormatFloat(rho, 'f', 3, 64), f1(P), "", "", "", "", "", "", ""}
+				oldRes, errOld := calculateWithConfig(paths[0], T, rho, P)
+				newRes, errNew := calculateWithConfig(paths[1], T, rho, P)
+				switch {
+				case errOld != nil || errNew != nil:
+					var msgs []string
+					if errOld != nil {
+						msgs = append(msgs, "旧："+errOld.Error())
+					} else {
+						rec[3], rec[6] = f1(oldRes.C), f1(oldRes.Tl)
+					}
+					if errNew != nil {
+						msgs = append(msgs, "新："+errNew.Error())
+					} else {
+						rec[4], rec[7] = f1(newRes.C), f1(newRes.Tl)
+					}
+					rec[9] = strings.Join(msgs, "；")
+					if (errOld == nil) != (errNew == nil) {
+						changed++
+					}
+				default:
+					dC := roundHalfUp(newRes.C-oldRes.C, 1)
+					dtl := roundHalfUp(newRes.Tl-oldRes.Tl, 1)
+					rec[3], rec[4], rec[5] = f1(oldRes.C), f1(newRes.C), f1(dC)
+					rec[6], rec[7], rec[8] = f1(oldRes.Tl), f1(newRes.Tl), f1(dtl)
+					if dC != 0 || dtl != 0 {
+						changed++
+					}
+					at := fmt.Sprintf("T=%g℃ rho=%.3f P=%gkPa", T, rho, P)
+					if math.Abs(dC) > math.Abs(maxDC) {
+						maxDC, maxDCAt = dC, at
+					}
+					if math.Abs(dtl) > math.Abs(maxDtl) {
+						maxDtl, maxDtlAt = dtl, at
+					}
+				}
+				if err := w.Write(rec); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(errOut, "%s → %s：%d个网格点中%d个结果变化\n", paths[0], paths[1], points, changed)
+	if maxDCAt != "" {
+		fmt.Fprintf(errOut, "浓度最大变化%+.1f%%（%s）\n", maxDC, maxDCAt)
+	}
+	if maxDtlAt != "" {
+		fmt.Fprintf(errOut, "溶液沸点最大变化%+.1f℃（%s）\n", maxDtl, maxDtlAt)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// dotNode 计算流程图中的一个节点
+type dotNode struct {
+	id, label string
+	input     bool // 输入量（以不同形状区分）
+}
+
+// 计算流程的依赖关系（起点 → 终点）
+var dotEdges = [][2]string{
+	{"T", "C"}, {"rho", "C"},
+	{"P", "tw"},
+	{"C", "bprAtm"}, {"tw", "bprAtm"},
+	{"tw", "K"},
+	{"bprAtm", "bpr"}, {"K", "bpr"},
+	{"tw", "tl"}, {"bpr", "tl"},
+}
+
+// writeDOT 以Graphviz DOT格式输出计算流程，节点标注实际数值（用 dot -Tpng 渲染）
+func writeDOT(w io.Writer, T, rho, P float64, s calcSteps) error {
+	nodes := []dotNode{
+		{"T", fmt.Sprintf("实测温度 T\n%.1f ℃", T), true},
+		{"rho", fmt.Sprintf("实测密度 rho\n%.3f g/cm³", rho), true},
+		{"P", fmt.Sprintf("工艺压力 P\n%.1f kPa", P), true},
+		{"C", fmt.Sprintf("反查浓度 C\n%.1f %%\n（%g℃/%g℃行插值）", s.C, s.tLeft, s.tRight), false},
+		{"tw", fmt.Sprintf("纯水沸点 tw\n%.1f ℃", s.tw), false},
+		{"bprAtm", fmt.Sprintf("常压BPR\n%.1f ℃", s.bprAtm), false},
+		{"K", fmt.Sprintf("压力修正 K\n%.4f", s.K), false},
+		{"bpr", fmt.Sprintf("极低负压BPR\n%.1f ℃", s.bpr), false},
+		{"tl", fmt.Sprintf("溶液沸点 tl\n%.1f ℃", s.tl), false},
+	}
+
+	if _, err := fmt.Fprintln(w, "digraph bpr {\n\trankdir=LR;\n\tnode [fontname=\"SimHei\"];"); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		shape := "box"
+		if n.input {
+			shape = "ellipse"
+		}
+		if _, err := fmt.Fprintf(w, "\t%s [shape=%s, label=%q];\n", n.id, shape, n.label); err != nil {
+			return err
+		}
+	}
+	for _, e := range dotEdges {
+		if _, err := fmt.Fprintf(w, "\t%s -> %s;\n", e[0], e[1]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 杜林图默认的浓度组（%）与压力范围（kPa，绝压）
+const (
+	defaultDuhringConcs    = "45,47,49,51,53"
+	defaultDuhringPressure = "8:28:1"
+)
+
+// parseConcentrationList 解析逗号分隔的浓度列表（%）
+func parseConcentrationList(spec string) ([]float64, error) {
+	var concs []float64
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		c, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("浓度%q不是数字", f)
+		}
+		concs = append(concs, c)
+	}
+	if len(concs) == 0 {
+		return nil, fmt.Errorf("浓度列表为空")
+	}
+	return concs, nil
+}
+
+// writeDuhringChart 杜林图数据：各浓度在各压力下的（纯水沸点, 溶液沸点），每个浓度一条线
+// 按浓度分组输出，组内压力升序；某点超出适用范围时错误写入error列，不中断其余各点
+func writeDuhringChart(out io.Writer, concs, pressures []float64) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"C", "P", "tw", "tl", "error"}); err != nil {
+		return err
+	}
+	for _, C := range concs {
+		for _, P := range pressures {
+			rec := []string{strconv.FormatFloat(C, 'f', -1, 64), strconv.FormatFloat(P, 'f', -1, 64)}
+			tw, tl, err := func() (tw, tl float64, err error) {
+				if tw, err = getPureWaterBoilingPoint(P); err != nil {
+					return 0, 0, err
+				}
+				_, _, _, tl, err = boilingPointForConcentration(C, tw)
+				return tw, tl, err
+			}()
+			if err != nil {
+				rec = append(rec, "", "", err.Error())
+			} else {
+				rec = append(rec, strconv.FormatFloat(tw, 'f', 1, 64), strconv.FormatFloat(tl, 'f', 1, 64), "")
+			}
+			if err := w.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// runDuhringChart duhring-chart子命令：lsg duhring-chart [-C 45,48,51] [-P 8:28:1]
+func runDuhringChart(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("duhring-chart", flag.ContinueOnError)
+	concSpec := fs.String("C", defaultDuhringConcs, "浓度列表（%），逗号分隔，每个浓度一条杜林线")
+	pressureSpec := fs.String("P", defaultDuhringPressure, "压力范围（kPa，绝压），格式 起点:终点:步长")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	concs, err := parseConcentrationList(*concSpec)
+	if err != nil {
+		return err
+	}
+	vals, err := parseColonFloats(*pressureSpec, 3)
+	if err != nil {
+		return err
+	}
+	pressures, err := sweepRange(vals[0], vals[1], vals[2])
+	if err != nil {
+		return err
+	}
+	return writeDuhringChart(out, concs, pressures)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 演示用的代表性样品（仅作示例，非实测数据）
+const (
+	demoT   = 60.0
+	demoRho = 1.45
+	demoP   = 20.0
+)
+
+// writeExplanation 逐步输出计算过程及各步依据，便于核对每个中间量的来历
+func writeExplanation(w io.Writer, T, rho, P float64, s calcSteps) error {
+	tOp := bprOperatingTemp(s.bprAtm, s.K, s.tw)
+	slope, intercept := bprCoefficientsAt(tOp)
+	kLo, kHi := kBounds()
+	kLimit := fmt.Sprintf("限制在[%.2f, %.2f]", kLo, kHi)
+	if opts.noClampK {
+		kLimit = "不限幅（-no-clamp-k）"
+	}
+	vaporMethod := "线性插值"
+	if opts.vapor == vaporLogLinear {
+		vaporMethod = "对ln P线性插值"
+	}
+	step1 := []string{
+		fmt.Sprintf("步骤1 反查浓度：T位于密度表%g℃与%g℃两行之间", s.tLeft, s.tRight),
+		fmt.Sprintf("  按同浓度下密度随温度线性变化，rho在两行中的等效密度为 %.4f / %.4f g/cm³", s.rhoLeft, s.rhoRight),
+		fmt.Sprintf("  在各行内按密度插值得浓度 %.2f%% / %.2f%%，再按温度插值得 C=%.1f%%", s.CLeft, s.CRight, s.C),
+	}
+	lines := []string{
+		fmt.Sprintf("输入：实测温度 T=%.1f℃，实测密度 rho=%.3f g/cm³，工艺压力 P=%.1f kPa（绝压）", T, rho, P),
+		"",
+	}
+	lines = append(lines, step1...)
+	lines = append(lines, []string{
+		fmt.Sprintf("步骤2 纯水沸点：P=%.1f kPa在蒸气压表中%s，tw=%.1f℃", P, vaporMethod, s.tw),
+		fmt.Sprintf("步骤3 常压BPR：工作温度（未校正的溶液沸点%.1f℃）所在分带的关联 %.4f×C%+.2f（不低于%.2f℃），bprAtm=%.2f℃", tOp, slope, intercept, bprFloorAt(tOp), s.bprAtm),
+		fmt.Sprintf("步骤4 压力修正：%s，%s，K=%.4f", kFormula(), kLimit, s.K),
+		fmt.Sprintf("步骤5 结果：BPR=bprAtm×K=%.1f℃，溶液沸点 tl=tw+BPR=%.1f℃", s.bpr, s.tl),
+	}...)
+	if opts.atmBoilingPoint != standardAtmBoilingPoint {
+		lines = append(lines, fmt.Sprintf("  （关联在当地常压沸点%g℃下测定，系数已按沸点升高常数折算到100℃参考：×%.4f）", opts.atmBoilingPoint, atmReferenceScale()))
+	}
+	if bprCalibration.points > 0 {
+		lines = append(lines, fmt.Sprintf("  （BPR已按%d个现场数据点校正：×%.3f%+.2f℃）", bprCalibration.points, bprCalibration.scale, bprCalibration.offset))
+	}
+	for _, warning := range s.warnings {
+		lines = append(lines, "警告："+warning)
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// runDemo 以内置示例样品走完整个计算流程并逐步注释（兼作冒烟测试）
+func runDemo(w io.Writer) error {
+	fmt.Fprintln(w, "=== 演示模式：以下输入仅为示例，并非实测数据 ===")
+	s, err := calculateSteps(demoT, demoRho, demoP)
+	if err != nil {
+		return err
+	}
+	return writeExplanation(w, demoT, demoRho, demoP, s)
+}
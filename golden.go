@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// 基准结果（golden）文件：在固定输入网格上运行calculate，记录输入与输出，重构前后对照以确认数值不变。
+// 隐藏模式，不列入-h：
+//
+//	lsg -dump-golden golden.csv    写出当前版本的基准结果
+//	lsg -check-golden golden.csv   按文件中的输入重算并逐项比较，有差异时以非零状态退出
+//
+// 网格取diffConfigGrid（样品密度由DensityFor生成并取3位小数），另加几组应报错的输入以锁定错误信息。
+// 仓库中的基准文件为testdata/golden.csv，由golden_test.go核对
+var goldenHeader = []string{"T", "rho", "P", "C", "tw", "bpr", "tl", "error"}
+
+// 数值比较容差：吸收不同平台浮点运算（如FMA）造成的末位差异，远小于输出的0.1精度
+const goldenTol = 1e-9
+
+// 辅助：应报错的输入（温度、压力、密度各超出一次）
+var goldenErrorInputs = [][3]float64{{110, 1.5, 20}, {70, 1.5, 5}, {70, 1.0, 20}}
+
+// goldenInputs 基准结果的输入网格(T, rho, P)
+func goldenInputs() ([][3]float64, error) {
+	temps, concs, pressures := diffConfigGrid()
+	var inputs [][3]float64
+	for _, T := range temps {
+		for _, C := range concs {
+			rho, err := DensityFor(T, C)
+			if err != nil {
+				return nil, fmt.Errorf("生成T=%g℃、C=%g%%的样品密度：%w", T, C, err)
+			}
+			for _, P := range pressures {
+				inputs = append(inputs, [3]float64{T, roundHalfUp(rho, 3), P})
+			}
+		}
+	}
+	return append(inputs, goldenErrorInputs...), nil
+}
+
+// 辅助：一组输入的基准记录（数值按%g写出，保留完整精度）
+func goldenRecord(in [3]float64) []string {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	rec := []string{format(in[0]), format(in[1]), format(in[2]), "", "", "", "", ""}
+	C, tw, bpr, tl, err := calculate(in[0], in[1], in[2])
+	if err != nil {
+		rec[7] = err.Error()
+		return rec
+	}
+	rec[3], rec[4], rec[5], rec[6] = format(C), format(tw), format(bpr), format(tl)
+	return rec
+}
+
+// dumpGolden 写出基准结果CSV
+func dumpGolden(out io.Writer) error {
+	inputs, err := goldenInputs()
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(out)
+	w.Write(goldenHeader)
+	for _, in := range inputs {
+		w.Write(goldenRecord(in))
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// checkGolden 按基准文件中的输入重算，逐行比较输出；差异逐条写到errOut，返回差异行数
+func checkGolden(in io.Reader, errOut io.Writer) (int, error) {
+	records, err := csv.NewReader(in).ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 || len(records[0]) != len(goldenHeader) {
+		return 0, fmt.Errorf("基准文件格式不符，首行应为 %v", goldenHeader)
+	}
+	var diffs int
+	for i, rec := range records[1:] {
+		var input [3]float64
+		for j := range input {
+			if input[j], err = strconv.ParseFloat(rec[j], 64); err != nil {
+				return diffs, fmt.Errorf("基准文件第%d行：%w", i+2, err)
+			}
+		}
+		got := goldenRecord(input)
+		for j := 3; j < len(goldenHeader); j++ {
+			if !goldenFieldEqual(got[j], rec[j], j) {
+				diffs++
+				fmt.Fprintf(errOut, "第%d行（T=%s，rho=%s，P=%s）%s：基准%q，当前%q\n",
+					i+2, rec[0], rec[1], rec[2], goldenHeader[j], rec[j], got[j])
+				break
+			}
+		}
+	}
+	return diffs, nil
+}
+
+// 辅助：比较一个输出字段；数值列在goldenTol内视为一致，错误信息逐字比较
+func goldenFieldEqual(got, want string, col int) bool {
+	if got == want {
+		return true
+	}
+	if goldenHeader[col] == "error" {
+		return false
+	}
+	g, err1 := strconv.ParseFloat(got, 64)
+	w, err2 := strconv.ParseFloat(want, 64)
+	return err1 == nil && err2 == nil && math.Abs(g-w) <= goldenTol
+}
+
+// 执行隐藏的基准结果模式：mode为-dump-golden或-check-golden
+func runGolden(mode, path string) error {
+	if mode == "-dump-golden" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := dumpGolden(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	diffs, err := checkGolden(f, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if diffs > 0 {
+		return fmt.Errorf("%d行与基准结果不一致", diffs)
+	}
+	fmt.Fprintln(os.Stderr, "与基准结果一致")
+	return nil
+}
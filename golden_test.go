@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// 按testdata/golden.csv重算全部输入，数值在goldenTol内一致、错误信息逐字一致。
+// 有意改变输出时以 go run . -dump-golden testdata/golden.csv 重新生成，并在提交说明中列出变化
+func TestGolden(t *testing.T) {
+	f, err := os.Open("testdata/golden.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var diffs strings.Builder
+	n, err := checkGolden(f, &diffs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n > 0 {
+		t.Errorf("%d行与基准结果不一致：\n%s", n, diffs.String())
+	}
+}
+
+func TestGoldenFieldEqual(t *testing.T) {
+	cases := []struct {
+		got, want string
+		col       int
+		eq        bool
+	}{
+		{"50.1", "50.1", 6, true},
+		{"50.1", "50.10000000000001", 6, true},
+		{"50.1", "50.2", 6, false},
+		{"", "50.1", 6, false},
+		{"温度仅支持20~100℃", "温度仅支持20~100℃", 7, true},
+		{"1", "1.0", 7, false}, // 错误信息不按数值比较
+	}
+	for _, c := range cases {
+		if got := goldenFieldEqual(c.got, c.want, c.col); got != c.eq {
+			t.Errorf("goldenFieldEqual(%q, %q, %s) = %v，应为%v", c.got, c.want, goldenHeader[c.col], got, c.eq)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 交互提示的输出位置（JSON模式下改为stderr，保持stdout只有数据）
+var promptOut io.Writer = os.Stdout
+
+// 各输入项可附带的单位（首项用于提示），输入带这些单位时去掉后照常解析
+var (
+	unitsTemperature = []string{"℃", "°C", "C"}
+	unitsDensity     = []string{"g/cm³", "g/cm3", "g/mL"}
+	unitsPressure    = []string{"kPa"}
+)
+
+// 交互输入共用的标准输入读取器：每次提示新建bufio.Reader会把预读的后续行丢在旧缓冲区里，
+// 管道一次送入多行（如 printf '70\n1.5\n25\n' | lsg）时第二项起读不到
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// 辅助：提示并读取一行（去掉首尾空白）
+func readLine(prompt string) (string, error) {
+	fmt.Fprint(promptOut, prompt)
+	input, err := stdinReader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// 辅助：标准输入是否为交互终端（字符设备）；管道、重定向的文件或/dev/null均不是
+// 仅用标准库判断，不依赖x/term
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(fi, null)
+}
+
+// densityReading 密度计读数：密度及其测量温度（形如"1.45@25"；未带@时测量温度即工艺温度）
+type densityReading struct {
+	rho, measT float64
+	tagged     bool // 是否带测量温度
+}
+
+func (d *densityReading) String() string {
+	if d == nil || !d.tagged {
+		if d == nil {
+			return "0"
+		}
+		return strconv.FormatFloat(d.rho, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%g@%g", d.rho, d.measT)
+}
+
+func (d *densityReading) Set(s string) error {
+	v, err := parseDensityReading(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// parseDensityReading 解析"密度"或"密度@测量温度"，两部分均可带各自单位（如"1.45g/mL@25℃"）
+func parseDensityReading(s string) (densityReading, error) {
+	rhoPart, tPart, tagged := strings.Cut(strings.TrimSpace(s), "@")
+	rho, err := parseInputNumber(strings.TrimSpace(rhoPart), unitsDensity)
+	if err != nil {
+		return densityReading{}, err
+	}
+	if !tagged {
+		return densityReading{rho: rho}, nil
+	}
+	measT, err := parseInputNumber(strings.TrimSpace(tPart), unitsTemperature)
+	if err != nil {
+		return densityReading{}, fmt.Errorf("测量温度：%w", err)
+	}
+	return densityReading{rho: rho, measT: measT, tagged: true}, nil
+}
+
+// at 工艺温度T下的密度：带测量温度且与T不同时按同浓度换算
+func (d densityReading) at(T float64) (float64, error) {
+	if !d.tagged || d.measT == T {
+		return d.rho, nil
+	}
+	return DensityAtProcessTemp(d.rho, d.measT, T)
+}
+
+// 辅助：解析输入的数值；数字后带本项单位时去掉，带其他后缀（如"1.45 kg"）时明确提示去掉单位
+// 逗号小数点、千分位与科学计数法按-decimal-sep统一（见normalizeNumber）
+func parseInputNumber(input string, units []string) (float64, error) {
+	num, err := normalizeNumber(input)
+	if err != nil {
+		return 0, err
+	}
+	if val, err := strconv.ParseFloat(num, 64); err == nil {
+		return val, nil
+	}
+	end := strings.IndexFunc(num, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E')
+	})
+	if end <= 0 {
+		return 0, fmt.Errorf("输入格式错误，请输入数字")
+	}
+	val, err := strconv.ParseFloat(num[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("输入格式错误，请输入数字")
+	}
+	suffix := num[end:]
+	for _, u := range units {
+		if strings.EqualFold(suffix, u) {
+			return val, nil
+		}
+	}
+	return 0, fmt.Errorf("输入%q带有无法识别的后缀%q：请去掉单位，只输入数字（本项单位为%s）", input, suffix, units[0])
+}
+
+// 命令行已指定的值直接使用，否则交互输入
+// check对取得的值做范围校验：交互输入不合格时在终端上说明原因（含有效范围）并重新输入，不必等全部输完才报错；
+// 命令行给出的值或非交互输入（管道）不合格时直接返回错误
+func inputValue(given bool, val float64, prompt string, units []string, check func(float64) error) (float64, error) {
+	if given {
+		return val, check(val)
+	}
+	return promptValid(prompt, func(line string) (float64, error) {
+		v, err := parseInputNumber(line, units)
+		if err != nil {
+			return 0, err
+		}
+		return v, check(v)
+	})
+}
+
+// 辅助：提示并读取一行，经parse解析校验；交互终端上出错时提示后重新输入，读到EOF或非交互时返回错误
+func promptValid[V any](prompt string, parse func(string) (V, error)) (V, error) {
+	for {
+		var v V
+		line, err := readLine(prompt)
+		if err != nil {
+			return v, err
+		}
+		v, err = parse(line)
+		if err == nil || !stdinIsTerminal() {
+			return v, err
+		}
+		fmt.Fprintf(promptOut, "输入有误：%v，请重新输入\n", err)
+	}
+}
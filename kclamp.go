@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// K限幅分析默认的压力带（kPa，绝压）：本工具的主要工况，深度真空段需加-deep-vacuum并自行给出范围
+const defaultKClampBand = "8:15:0.5"
+
+// K限幅分析所取的浓度（%）：BPR适用区间内每1%
+func kClampConcentrations() []float64 {
+	concs, _ := sweepRange(bprMinC, bprMaxC, 1)
+	return concs
+}
+
+// runKClampAnalysis 压力修正系数K限幅的影响：在压力带内逐点比较限幅与不限幅时的溶液沸点
+// 输出CSV：P,tw,K_raw,K,C,tl,tl_unclamped,diff；汇总（限幅生效点数、最大偏差）写到errOut
+// K = 1 + 0.0015×(100 − tw)在tw低于40℃（约7.5kPa以下）时才超过上限1.09，8kPa时tw=41.2℃、K=1.0882，
+// 因此8~15kPa内限幅不生效，偏差为0；限幅实际影响的是-deep-vacuum下的工况
+func runKClampAnalysis(spec string, out, errOut io.Writer) error {
+	vals, err := parseColonFloats(spec, 3)
+	if err != nil {
+		return err
+	}
+	pressures, err := sweepRange(vals[0], vals[1], vals[2])
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"P", "tw", "K_raw", "K", "C", "tl", "tl_unclamped", "diff"}); err != nil {
+		return err
+	}
+	format := func(v float64, digits int) string { return strconv.FormatFloat(v, 'f', digits, 64) }
+	var points, clamped int
+	var maxDiff, maxDiffP, maxDiffC float64
+	for _, P := range pressures {
+		tw, err := getPureWaterBoilingPoint(P)
+		if err != nil {
+			return err
+		}
+		K, raw := pressureCorrection(tw)
+		for _, C := range kClampConcentrations() {
+			bprAtm, _, _, tl, err := boilingPointForConcentration(C, tw)
+			if err != nil {
+				return err
+			}
+			tlRaw := roundHalfUp(tw+roundHalfUp(bprCalibration.apply(bprAtm*raw), 1), 1)
+			diff := roundHalfUp(tlRaw-tl, 1)
+			points++
+			if K != raw {
+				clamped++
+			}
+			if math.Abs(diff) > math.Abs(maxDiff) {
+				maxDiff, maxDiffP, maxDiffC = diff, P, C
+			}
+			rec := []string{format(P, -1), format(tw, 1), format(raw, 4), format(K, 4),
+				format(C, -1), format(tl, 1), format(tlRaw, 1), format(diff, 1)}
+			if err := w.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	kLo, kHi := kBounds()
+	fmt.Fprintf(errOut, "K限幅[%.2f, %.2f]在%g~%gkPa内：%d/%d个点生效", kLo, kHi, vals[0], vals[1], clamped, points)
+	if clamped == 0 {
+		fmt.Fprintln(errOut, "，限幅对该压力带无影响")
+		return nil
+	}
+	fmt.Fprintf(errOut, "，不限幅时溶液沸点最多相差%+.1f℃（P=%gkPa，C=%g%%）\n", maxDiff, maxDiffP, maxDiffC)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// 默认的压力修正曲线（纯水沸点tw℃, K）：两点连线即 K = 1 + 0.0015×(100 − tw)，
+// 两端点分别落在限幅上下限（tw=40℃时K=1.09，tw=220/3≈73.3℃时K=1.04），曲线外取端点值即原限幅
+var defaultKCorrectionTable = [][2]float64{{40, maxPressureK}, {220.0 / 3, minPressureK}}
+
+// 当前使用的压力修正曲线，按tw升序；现场可在-config的k_table中给出实测曲线替换
+var kCorrectionTable = defaultKCorrectionTable
+
+// validateKTable 校验K曲线：至少两点，tw严格递增，K为正
+func validateKTable(table [][2]float64) error {
+	if len(table) < 2 {
+		return fmt.Errorf("K曲线至少需要2个点，当前%d个", len(table))
+	}
+	for i, p := range table {
+		if p[1] <= 0 {
+			return fmt.Errorf("K曲线第%d点（tw=%g℃）的K=%g不为正", i+1, p[0], p[1])
+		}
+		if i > 0 && p[0] <= table[i-1][0] {
+			return fmt.Errorf("K曲线的tw应严格递增：第%d点%g℃不大于前一点%g℃", i+1, p[0], table[i-1][0])
+		}
+	}
+	return nil
+}
+
+// 辅助：tw所在的K曲线区间序号（两端外取首末区间，断点处取右侧区间）
+func kSegment(table [][2]float64, tw float64) int {
+	n := len(table)
+	i := 0
+	for i < n-2 && tw >= table[i+1][0] {
+		i++
+	}
+	return i
+}
+
+// kCurveAt 纯水沸点tw下K曲线table的取值：raw为按所在区间线性插值（曲线外按首末区间外推），K为曲线外取端点值后的结果
+func kCurveAt(table [][2]float64, tw float64) (K, raw float64) {
+	i := kSegment(table, tw)
+	p0, p1 := table[i], table[i+1]
+	raw = p0[1] + (tw-p0[0])*(p1[1]-p0[1])/(p1[0]-p0[0])
+	switch n := len(table); {
+	case tw < table[0][0]:
+		return table[0][1], raw
+	case tw > table[n-1][0]:
+		return table[n-1][1], raw
+	}
+	return raw, raw
+}
+
+// kCurveSlope K曲线在tw处的斜率dK/dtw（断点处取右侧区间，曲线外取外推区间）
+func kCurveSlope(tw float64) float64 {
+	i := kSegment(kCorrectionTable, tw)
+	p0, p1 := kCorrectionTable[i], kCorrectionTable[i+1]
+	return (p1[1] - p0[1]) / (p1[0] - p0[0])
+}
+
+// KParams 压力修正及最终组装所用的参数，与全局设置分离，便于以已知输入单独核对
+type KParams struct {
+	Table       [][2]float64  // K曲线（tw℃, K），按tw升序
+	NoClamp     bool          // 不限幅：曲线外按首末区间外推（-no-clamp-k）
+	Calibration bprCorrection // 现场BPR校正（-config的calibration）
+}
+
+// currentKParams 当前设置下的参数：K曲线kCorrectionTable、opts.noClampK与bprCalibration
+func currentKParams() KParams {
+	return KParams{Table: kCorrectionTable, NoClamp: opts.noClampK, Calibration: bprCalibration}
+}
+
+// correction 纯水沸点tw下的压力修正系数K及限幅前的值raw（说明见pressureCorrection）
+func (p KParams) correction(tw float64) (K, raw float64) {
+	K, raw = kCurveAt(p.Table, tw)
+	if p.NoClamp {
+		return raw, raw
+	}
+	return K, raw
+}
+
+// kBounds K曲线上K的最小、最大值（默认曲线即限幅[1.04, 1.09]）
+func kBounds() (lo, hi float64) {
+	lo, hi = kCorrectionTable[0][1], kCorrectionTable[0][1]
+	for _, p := range kCorrectionTable[1:] {
+		lo, hi = min(lo, p[1]), max(hi, p[1])
+	}
+	return lo, hi
+}
+
+// kFormula K的计算方式说明（用于逐步解释）
+func kFormula() string {
+	if slices.Equal(kCorrectionTable, defaultKCorrectionTable) {
+		return "K=1+0.0015×(100−tw)"
+	}
+	return fmt.Sprintf("K按现场K曲线（%d点）对tw插值", len(kCorrectionTable))
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 插值恰在端点时返回端点值本身，不带浮点误差
+func TestLinearInterpEndpoints(t *testing.T) {
+	cases := []struct {
+		x, x0, y0, x1, y1, want float64
+	}{
+		{1.512, 1.512, 50, 1.527, 51, 50},
+		{1.527, 1.512, 50, 1.527, 51, 51},
+		{1.527, 1.512, 45, 1.527, 0.7, 0.7}, // 按公式计算得0.7000000000000028
+		{5, 5, 7, 5, 9, 7},                  // 退化区间取y0
+	}
+	for _, c := range cases {
+		if got := linearInterp(c.x, c.x0, c.y0, c.x1, c.y1); got != c.want {
+			t.Errorf("linearInterp(%g, %g, %g, %g, %g) = %v，应为%v", c.x, c.x0, c.y0, c.x1, c.y1, got, c.want)
+		}
+	}
+}
+
+// T恰为表中温度时按其所在区间[左行, T]正常插值（权重全在T行），结果与从左侧逼近该温度一致，
+// 浓度区间同样限制在两行的共有范围内，不因恰好命中表中温度而跳变
+func TestConcentrationAtTableTemperature(t *testing.T) {
+	cases := []struct {
+		T, rho float64
+		want   float64
+		clamp  bool
+	}{
+		{60, 1.512, 50, false},  // 60℃行的表点
+		{60, 1.527, 51, false},  // 60℃行的表点
+		{50, 1.505, 50, false},  // 50℃行的表点
+		{100, 1.392, 50, false}, // 表中最高温度
+		{20, 1.569, 50, false},  // 表中最低温度
+		{60, 1.556, 51.8, true}, // 超出55℃行上限51.8%：与T=59.9时同样取端点值
+	}
+	for _, c := range cases {
+		s, err := getConcentrationSteps(context.Background(), c.T, c.rho)
+		if err != nil {
+			t.Errorf("T=%g rho=%g：%v", c.T, c.rho, err)
+			continue
+		}
+		if s.C != c.want {
+			t.Errorf("T=%g rho=%g：C=%v，应为%v", c.T, c.rho, s.C, c.want)
+		}
+		if got := len(s.clamps) > 0; got != c.clamp {
+			t.Errorf("T=%g rho=%g：端点限幅=%v，应为%v", c.T, c.rho, got, c.clamp)
+		}
+	}
+
+	// 与相邻温度连续：T=60与59.9得同一端点浓度
+	below, err := getConcentration(59.9, 1.556)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at, err := getConcentration(60, 1.556)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if below != at {
+		t.Errorf("rho=1.556：T=59.9得%v%%，T=60得%v%%，跨表中温度跳变", below, at)
+	}
+}
+
+// 两行浓度断点不同时按断点并集建立T下的浓度-密度关联：50℃行到52%、55℃行到51.8%，
+// 共有区间上端取55℃行独有的51.8，而不是50℃行在共有区间内的最后一个断点51
+func TestConvertDensityToAdjacentTemps(t *testing.T) {
+	cases := []struct {
+		T, rho         float64
+		left, right, C float64
+		clamp          bool
+	}{
+		{52.5, 1.45, 1.448, 1.452, 0, false},
+		{52.5, 1.53, 1.525, 1.535, 51.4, false}, // 只取左行断点时截断为51.0
+		{52.5, 1.535, 1.53, 1.54, 51.8, false},
+		{52.5, 1.54, 1.53, 1.54, 51.8, true}, // 超出共有区间上端51.8
+		{55, 1.535, 1.525, 1.535, 51.4, false},
+		{55, 1.54, 1.53, 1.54, 51.8, false}, // 只取左行断点时截断为51.0
+		{55, 1.545, 1.53, 1.54, 51.8, true},
+		{57.5, 1.535, 1.536, 1.534, 0, false}, // 55℃、60℃两行断点亦不同
+	}
+	for _, c := range cases {
+		left, right, clamp, err := convertDensityToAdjacentTemps(c.T, c.rho)
+		if err != nil {
+			t.Errorf("T=%g rho=%g：%v", c.T, c.rho, err)
+			continue
+		}
+		if left != c.left || right != c.right {
+			t.Errorf("T=%g rho=%g：相邻温度密度%v、%v，应为%v、%v", c.T, c.rho, left, right, c.left, c.right)
+		}
+		if got := clamp != nil; got != c.clamp {
+			t.Errorf("T=%g rho=%g：端点限幅=%v，应为%v", c.T, c.rho, got, c.clamp)
+		}
+		if c.C == 0 {
+			continue
+		}
+		C, err := getConcentration(c.T, c.rho)
+		if err != nil {
+			t.Errorf("T=%g rho=%g：%v", c.T, c.rho, err)
+		} else if C != c.C {
+			t.Errorf("T=%g rho=%g：C=%v，应为%v", c.T, c.rho, C, c.C)
+		}
+	}
+
+	got := mergedConcentrations(densityRows()[50], densityRows()[55])
+	want := []float64{0, 20, 25, 30, 34, 35, 38, 40, 42, 45, 46, 48, 49, 50, 51, 51.8, 52}
+	if !slices.Equal(got, want) {
+		t.Errorf("50℃与55℃行断点并集%v，应为%v", got, want)
+	}
+}
+
+// 相邻两行浓度区间不重叠时报告没有共有浓度区间（而不是笼统的数据不足），行温度按原值显示
+func TestConvertDensityNoSharedRange(t *testing.T) {
+	saved := densityRows()
+	t.Cleanup(func() { setDensityTable(saved) })
+	setDensityTable(map[float64][][2]float64{
+		52.5: {{0, 1.000}, {20, 1.160}, {30, 1.263}},
+		60:   {{40, 1.368}, {48, 1.482}, {53, 1.557}},
+	})
+
+	_, _, _, err := convertDensityToAdjacentTemps(55, 1.3)
+	if err == nil {
+		t.Fatal("两行浓度区间不重叠时应报错")
+	}
+	want := "52.5℃行（浓度0.0~30.0%）与60℃行（浓度40.0~53.0%）没有共有浓度区间"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("错误%q，应包含%q", err, want)
+	}
+}
+
+// 默认网格上浓度反查往返误差不超过inversionTolerance（同-check-inversion）
+func TestValidateInversion(t *testing.T) {
+	worst, err := validateInversion(defaultInversionGrid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(worst.diff()) > inversionTolerance {
+		t.Errorf("最大往返误差%+.3f%%（T=%g℃，C=%g%%，反查%g%%），超出容差±%g%%",
+			worst.diff(), worst.T, worst.C, worst.CBack, inversionTolerance)
+	}
+}
+
+// 55℃行上端：该行独有的断点51.8%（rho=1.540）反查回51.8%，不再截断为50℃行的51.0%，往返一致
+func TestConcentrationUpperEnd55(t *testing.T) {
+	C, err := getConcentration(55, 1.540)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if C != 51.8 {
+		t.Errorf("T=55 rho=1.540：C=%v，应为51.8", C)
+	}
+	r, err := validateInversion([]float64{55}, []float64{51, 51.5, 51.8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.diff()) > inversionTolerance {
+		t.Errorf("55℃上端往返误差%+.3f%%（C=%g%%，反查%g%%）", r.diff(), r.C, r.CBack)
+	}
+}
+
+// 辅助：改为二分查找前的顺序扫描（对照用）
+func scanDensityByConcentration(c float64, pairs [][2]float64) float64 {
+	n := len(pairs)
+	if c <= pairs[0][0] {
+		return pairs[0][1]
+	}
+	if c >= pairs[n-1][0] {
+		return pairs[n-1][1]
+	}
+	for i := 0; i < n-1; i++ {
+		if c >= pairs[i][0] && c <= pairs[i+1][0] {
+			return linearInterp(c, pairs[i][0], pairs[i][1], pairs[i+1][0], pairs[i+1][1])
+		}
+	}
+	return math.NaN()
+}
+
+func scanConcentrationByDensity(rho float64, pairs [][2]float64) float64 {
+	n := len(pairs)
+	if rho <= pairs[0][1] {
+		return pairs[0][0]
+	}
+	if rho >= pairs[n-1][1] {
+		return pairs[n-1][0]
+	}
+	for i := 0; i < n-1; i++ {
+		if rho >= pairs[i][1] && rho <= pairs[i+1][1] {
+			return linearInterp(rho, pairs[i][1], pairs[i][0], pairs[i+1][1], pairs[i+1][0])
+		}
+	}
+	return math.NaN()
+}
+
+// 辅助：行内各断点、断点两侧紧邻值、相邻断点中点及两端外的取值
+func probePoints(xs []float64) []float64 {
+	var pts []float64
+	for i, x := range xs {
+		pts = append(pts, x, math.Nextafter(x, math.Inf(-1)), math.Nextafter(x, math.Inf(1)))
+		if i > 0 {
+			pts = append(pts, (xs[i-1]+x)/2)
+		}
+	}
+	return append(pts, xs[0]-1, xs[len(xs)-1]+1)
+}
+
+// 二分查找与顺序扫描对内置密度表各行逐点给出相同结果（含恰为断点与两端外）
+func TestInterpBinarySearchMatchesScan(t *testing.T) {
+	withOpts(t, func(o *calcOptions) { o.concInterp = concInterpLinear })
+	for T, pairs := range densityRows() {
+		cs := make([]float64, len(pairs))
+		rhos := make([]float64, len(pairs))
+		for i, p := range pairs {
+			cs[i], rhos[i] = p[0], p[1]
+		}
+		for _, c := range probePoints(cs) {
+			got, err := interpDensityByConcentration(c, pairs, T)
+			if want := scanDensityByConcentration(c, pairs); err != nil || got != want {
+				t.Errorf("%g℃行 c=%v：二分得%v（%v），顺序扫描得%v", T, c, got, err, want)
+			}
+		}
+		for _, rho := range probePoints(rhos) {
+			got, err := interpConcentrationByDensity(rho, pairs, T)
+			if want := scanConcentrationByDensity(rho, pairs); err != nil || got != want {
+				t.Errorf("%g℃行 rho=%v：二分得%v（%v），顺序扫描得%v", T, rho, got, err, want)
+			}
+		}
+	}
+
+	// 恰为断点时得表中值本身
+	pairs := densityRows()[60]
+	if rho, _ := interpDensityByConcentration(51, pairs, 60); rho != 1.527 {
+		t.Errorf("60℃行c=51：%v，应为表中1.527", rho)
+	}
+	if c, _ := interpConcentrationByDensity(1.527, pairs, 60); c != 51 {
+		t.Errorf("60℃行rho=1.527：%v，应为表中51", c)
+	}
+}
+
+// rhoT相同的浓度按浓度升序排列：无论输入顺序，区间选取与结果都相同
+func TestInterpConcentrationByTempDensityTies(t *testing.T) {
+	base := []tempDensity{{48, 1.48}, {49, 1.50}, {50, 1.50}, {51, 1.53}, {52, 1.53}}
+	cases := []struct {
+		rho, want float64
+	}{
+		{1.50, 49},         // 恰为重复密度：取区间[48, 49]的右端点，即较低的浓度（按浓度降序则得50）
+		{1.51, 50 + 1.0/3}, // 越过重复点后在[50, 51]区间插值
+		{1.53, 52},         // 上端重复：取排序后的最后一项，即较高的浓度
+	}
+	perms := [][]int{{0, 1, 2, 3, 4}, {4, 3, 2, 1, 0}, {2, 1, 0, 4, 3}, {1, 2, 4, 3, 0}}
+	for _, c := range cases {
+		for _, perm := range perms {
+			list := make([]tempDensity, len(base))
+			for i, j := range perm {
+				list[i] = base[j]
+			}
+			got, _, err := interpConcentrationByTempDensity(55, c.rho, list)
+			if err != nil {
+				t.Fatalf("rho=%g：%v", c.rho, err)
+			}
+			if math.Abs(got-c.want) > 1e-9 {
+				t.Errorf("rho=%g 输入顺序%v：C=%v，应为%v", c.rho, perm, got, c.want)
+			}
+		}
+	}
+}
+
+// 恰在一半处一律进位（负数按绝对值），经浮点运算略低于一半的恰好值同样进位，明显低于一半的舍去
+func TestRoundHalfUp(t *testing.T) {
+	cases := []struct {
+		x      float64
+		digits int
+		want   float64
+	}{
+		{51.25, 1, 51.3},
+		{51.15, 1, 51.2},    // 二进制下为51.149999999999998
+		{0.7 * 1.5, 1, 1.1}, // 浮点乘积1.0499999999999998，math.Round得1.0
+		{2.675, 2, 2.68},
+		{0.05, 1, 0.1},
+		{51.2499, 1, 51.2},
+		{51.2499999, 1, 51.2}, // 比一半低1e-7，超出浮点误差的容许范围
+		{51.24, 1, 51.2},
+		{-51.25, 1, -51.3},
+		{-46.45, 1, -46.5},
+		{-0.05, 1, -0.1},
+		{-51.2499, 1, -51.2},
+		{-51.24, 1, -51.2},
+		{1.0005, 3, 1.001},
+		{1.00049, 3, 1},
+	}
+	for _, c := range cases {
+		if got := roundHalfUp(c.x, c.digits); got != c.want {
+			t.Errorf("roundHalfUp(%v, %d) = %v，应为%v", c.x, c.digits, got, c.want)
+		}
+	}
+}
+
+// 连续提示共用同一读取器：一次送入的多行逐行读出，不因预读而丢失
+func TestReadLineSharedReader(t *testing.T) {
+	savedReader, savedOut := stdinReader, promptOut
+	t.Cleanup(func() { stdinReader, promptOut = savedReader, savedOut })
+	stdinReader = bufio.NewReader(strings.NewReader("70\n 1.5 \n25\n"))
+	promptOut = io.Discard
+
+	for _, want := range []string{"70", "1.5", "25"} {
+		got, err := readLine("> ")
+		if err != nil || got != want {
+			t.Errorf("readLine = %q, %v，应为%q", got, err, want)
+		}
+	}
+	if _, err := readLine("> "); err != io.EOF {
+		t.Errorf("输入读完后应返回io.EOF，实际%v", err)
+	}
+}
+
+// 默认只有referenceBPRFit一组；追加分带后按工作温度线性插值，两端外取端点值，各带在同一浓度处触底
+func TestBPRCoefficientBands(t *testing.T) {
+	if len(bprCoefficientTable) != 1 || bprCoefficientTable[0] != referenceBPRFit {
+		t.Fatalf("默认BPR系数表%v，应只有referenceBPRFit", bprCoefficientTable)
+	}
+	saved := bprCoefficientTable
+	t.Cleanup(func() { bprCoefficientTable = saved })
+	low := bprCoefficients{T: 60, slope: 0.7, intercept: -23}
+	high := referenceBPRFit
+	bprCoefficientTable = []bprCoefficients{low, high}
+
+	cases := []struct {
+		T                float64
+		slope, intercept float64
+	}{
+		{40, low.slope, low.intercept}, // 低于最低分带取端点
+		{60, low.slope, low.intercept},
+		{80, (low.slope + high.slope) / 2, (low.intercept + high.intercept) / 2},
+		{100, high.slope, high.intercept},
+		{115, high.slope, high.intercept}, // 常压工况取100℃带
+	}
+	cFloor := (bprAtmFloor - referenceBPRFit.intercept) / referenceBPRFit.slope
+	for _, c := range cases {
+		slope, intercept := bprTableCoefficientsAt(c.T)
+		if math.Abs(slope-c.slope) > 1e-12 || math.Abs(intercept-c.intercept) > 1e-12 {
+			t.Errorf("T=%g：系数%.6f/%.4f，应为%.6f/%.4f", c.T, slope, intercept, c.slope, c.intercept)
+		}
+		if got, want := bprFloorAt(c.T), slope*cFloor+intercept; math.Abs(got-want) > 1e-9 {
+			t.Errorf("T=%g：下限%v，应为该带在%.2f%%处的取值%v", c.T, got, cFloor, want)
+		}
+	}
+	if got := bprFloorAt(100); got != bprAtmFloor {
+		t.Errorf("100℃带下限%v，应恰为%v", got, bprAtmFloor)
+	}
+}
+
+// 工作温度迭代收敛：以所得模型溶液沸点为工作温度重算，常压BPR不变（未校正时即tl）
+func TestBoilingPointOperatingTemperature(t *testing.T) {
+	for _, P := range []float64{8, 20, 28} {
+		tw, err := getPureWaterBoilingPoint(P)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, C := range []float64{45, 49, 53} {
+			bprAtm, K, _, tl, err := boilingPointForConcentration(C, tw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := calculateBPRAtmospheric(C, tl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != bprAtm {
+				t.Errorf("C=%g P=%g：工作温度%v下常压BPR %v，迭代结果%v", C, P, tl, again, bprAtm)
+			}
+			if want := roundHalfUp(tw+roundHalfUp(bprAtm*K, 1), 1); tl != want {
+				t.Errorf("C=%g P=%g：tl=%v，应为%v", C, P, tl, want)
+			}
+		}
+	}
+}
+
+// 蒸发水量：20℃密度换算质量后按溶质守恒，48%→52%、2m³（1.540g/cm³）需蒸发3080×4/52 kg
+func TestWaterToEvaporate(t *testing.T) {
+	got, err := WaterToEvaporate(48, 52, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3080.0 * 4 / 52; math.Abs(got-want) > 1e-6 {
+		t.Errorf("WaterToEvaporate(48, 52, 2) = %v，应为%v", got, want)
+	}
+
+	for _, c := range []struct{ startC, targetC, vol float64 }{
+		{52, 50, 1}, // 目标浓度不高于起始浓度
+		{48, 60, 1}, // 超出20℃密度表
+		{48, 52, 0}, // 体积非正
+	} {
+		if _, err := WaterToEvaporate(c.startC, c.targetC, c.vol); err == nil {
+			t.Errorf("WaterToEvaporate(%g, %g, %g)：应返回错误", c.startC, c.targetC, c.vol)
+		}
+	}
+}
+
+// 最高允许压力往返校验：该压力下正向计算的沸点不超过上限，再高0.02kPa（二分精度0.01kPa、向下取整0.01kPa）即超限
+// 20℃、1.569g/cm³恰为密度表中50%的表点
+func TestMaxPressureForBoilingLimit(t *testing.T) {
+	for _, maxTl := range []float64{60, 70, 75} { // 50%在8~28kPa内的沸点约为54.6~79.9℃
+		P, err := MaxPressureForBoilingLimit(50, maxTl)
+		if err != nil {
+			t.Fatalf("上限%g℃：%v", maxTl, err)
+		}
+		C, _, _, tl, err := calculate(20, 1.569, P)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if C != 50 || tl > maxTl {
+			t.Errorf("上限%g℃：P=%v下C=%v tl=%v，应为50%%且不超过上限", maxTl, P, C, tl)
+		}
+		if _, _, _, tl, err = calculate(20, 1.569, P+0.02); err != nil || tl <= maxTl {
+			t.Errorf("上限%g℃：P=%v下tl=%v（%v），应已超限", maxTl, P+0.02, tl, err)
+		}
+	}
+
+	if P, err := MaxPressureForBoilingLimit(50, 85); err != nil || P != pressureRange().Max {
+		t.Errorf("上限85℃：P=%v（%v），最高压力下仍未超限时应为压力上限", P, err)
+	}
+	if _, err := MaxPressureForBoilingLimit(50, 50); err == nil {
+		t.Error("上限50℃：最低压力下已超限，应返回错误")
+	}
+}
+
+// 设定值反求往返校验：所得密度正向计算的溶液沸点回到目标值（正向流程中密度、浓度与BPR各自取整，容差0.1℃）
+func TestDensityForBoilingPoint(t *testing.T) {
+	for _, target := range []float64{70, 72, 74} { // 60℃、20kPa下45~53%的沸点约69~75℃
+		rho, err := DensityForBoilingPoint(60, 20, target)
+		if err != nil {
+			t.Fatalf("目标%g℃：%v", target, err)
+		}
+		_, _, _, tl, err := calculate(60, rho, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(tl-target) > 0.1+1e-9 {
+			t.Errorf("目标%g℃：rho=%v正向计算得tl=%v", target, rho, tl)
+		}
+	}
+
+	if _, err := DensityForBoilingPoint(60, 20, 59); err == nil {
+		t.Error("目标59℃：低于20kPa下的纯水沸点59.7℃，应返回错误")
+	}
+	if _, err := DensityForBoilingPoint(60, 20, 90); err == nil {
+		t.Error("目标90℃：需超出BPR适用区间的浓度，应返回错误")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// 批量与扫描模式以NDJSON输出（-output-ndjson）：每处理完一行即写出一个JSON对象，下游可边算边读
+var outputNDJSON bool
+
+// recordWriter 批量、扫描结果的逐行写出；首行为列名。*csv.Writer即满足
+type recordWriter interface {
+	Write(rec []string) error
+	Flush()
+	Error() error
+}
+
+// 辅助：按输出格式创建写出器
+func newRecordWriter(out io.Writer) recordWriter {
+	if outputNDJSON {
+		return &ndjsonWriter{w: out}
+	}
+	return csv.NewWriter(out)
+}
+
+// ndjsonWriter 首行记为键名，此后每行写为一个按列顺序排列的JSON对象：合乎JSON写法的数字写作数字，
+// 空值（计算失败时的结果列、无错误时的error列）省略。每行直接写入out，不在内存中积累
+type ndjsonWriter struct {
+	w      io.Writer
+	header []string
+	err    error
+}
+
+func (n *ndjsonWriter) Write(rec []string) error {
+	if n.err != nil {
+		return n.err
+	}
+	if n.header == nil {
+		n.header = rec
+		return nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, v := range rec {
+		if v == "" || i >= len(n.header) {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(n.header[i])
+		b.Write(key)
+		b.WriteByte(':')
+		if _, err := strconv.ParseFloat(v, 64); err == nil && json.Valid([]byte(v)) {
+			b.WriteString(v)
+		} else {
+			val, _ := json.Marshal(v)
+			b.Write(val)
+		}
+	}
+	b.WriteString("}\n")
+	_, n.err = n.w.Write(b.Bytes())
+	return n.err
+}
+
+func (n *ndjsonWriter) Flush() {}
+
+func (n *ndjsonWriter) Error() error { return n.err }
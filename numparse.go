@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 数字输入的小数点写法（-decimal-sep）
+const (
+	decimalSepAuto  = "auto" // 自动判断：逗号、点同时出现时以后出现者为小数点；只有一个逗号且其后恰为3位数字时有歧义，报错
+	decimalSepDot   = "."    // 点为小数点，逗号为千分位
+	decimalSepComma = ","    // 逗号为小数点，点为千分位（欧洲写法，如 1,45）
+)
+
+// 交互输入与批量文件中数字的小数点写法
+var decimalSep = decimalSepAuto
+
+// 校验-decimal-sep
+func validateDecimalSep(sep string) error {
+	switch sep {
+	case decimalSepAuto, decimalSepDot, decimalSepComma:
+		return nil
+	}
+	return fmt.Errorf("未知的小数点写法%q（可选 auto|.|,）", sep)
+}
+
+// normalizeNumber 将各地区写法的数字统一为strconv可解析的形式：去掉空格类千分位（含不间断空格），
+// 按decimalSep区分小数点与千分位。科学计数法（1.45e0）原样保留
+func normalizeNumber(s string) (string, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	sep := decimalSep
+	if sep == decimalSepAuto {
+		var err error
+		if sep, err = detectDecimalSep(s); err != nil {
+			return "", err
+		}
+	}
+	if sep == decimalSepComma {
+		s = strings.ReplaceAll(s, ".", "")
+		return strings.ReplaceAll(s, ",", "."), nil
+	}
+	return strings.ReplaceAll(s, ",", ""), nil
+}
+
+// 辅助：自动判断小数点
+func detectDecimalSep(s string) (string, error) {
+	commas, dots := strings.Count(s, ","), strings.Count(s, ".")
+	switch {
+	case commas > 0 && dots > 0:
+		if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+			return decimalSepComma, nil
+		}
+		return decimalSepDot, nil
+	case commas == 1:
+		i := strings.Index(s, ",")
+		digits := 0
+		for _, r := range s[i+1:] {
+			if r < '0' || r > '9' {
+				break
+			}
+			digits++
+		}
+		if digits == 3 {
+			return "", fmt.Errorf("数字%q中的逗号可能是小数点也可能是千分位，请用-decimal-sep指定", s)
+		}
+		return decimalSepComma, nil
+	case dots > 1:
+		return decimalSepComma, nil // 1.450.000：点只能是千分位
+	}
+	return decimalSepDot, nil
+}
+
+// 辅助：解析以冒号分隔的若干数值（如 45:51:10）
+func parseColonFloats(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != n {
+		return nil, fmt.Errorf("%q格式错误，应为%d个以冒号分隔的数字", s, n)
+	}
+	vals := make([]float64, n)
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q格式错误，%q不是数字", s, p)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// 温度行内（浓度-密度）的插值方式
+//
+// 同一温度下密度随浓度略呈下凸，稀疏断点之间线性插值会高估区间中部的密度。
+// 留一交叉验证（去掉一个断点，用其余断点插值回该点）：
+//   - 20℃行去掉10%点（0→15%区间）：线性偏高0.0027 g/cm³，PCHIP偏高0.0003
+//   - 40℃行去掉15%点（0→20%区间）：线性偏高0.0053，PCHIP偏高0.0014
+//   - 60℃行去掉32%点（0→36%区间）：线性偏高0.0129，PCHIP偏高0.0042
+//
+// 高浓度区（45%以上）断点密集，两者相差在0.001以内
+const (
+	concInterpLinear = "linear"
+	concInterpPCHIP  = "pchip"
+)
+
+// 辅助：校验-conc-interp取值
+func validateConcInterp(method string) error {
+	if method != concInterpLinear && method != concInterpPCHIP {
+		return fmt.Errorf("未知的插值方式%q（可选 linear|pchip）", method)
+	}
+	return nil
+}
+
+// pchipSlopes 分段三次Hermite单调插值（PCHIP，Fritsch-Carlson加权调和平均）各断点的导数
+// 数据单调时插值曲线保持单调，按密度反查浓度仍有唯一解
+func pchipSlopes(pairs [][2]float64) []float64 {
+	n := len(pairs)
+	m := make([]float64, n)
+	h := make([]float64, n-1)
+	d := make([]float64, n-1)
+	for i := 0; i < n-1; i++ {
+		h[i] = pairs[i+1][0] - pairs[i][0]
+		d[i] = (pairs[i+1][1] - pairs[i][1]) / h[i]
+	}
+	if n == 2 {
+		m[0], m[1] = d[0], d[0]
+		return m
+	}
+	for i := 1; i < n-1; i++ {
+		if d[i-1]*d[i] <= 0 {
+			continue
+		}
+		w1, w2 := 2*h[i]+h[i-1], h[i]+2*h[i-1]
+		m[i] = (w1 + w2) / (w1/d[i-1] + w2/d[i])
+	}
+	// 端点：三点单侧差分，并限制其不破坏单调性
+	end := func(h0, h1, d0, d1 float64) float64 {
+		s := ((2*h0+h1)*d0 - h0*d1) / (h0 + h1)
+		switch {
+		case s*d0 <= 0:
+			return 0
+		case d0*d1 <= 0 && math.Abs(s) > math.Abs(3*d0):
+			return 3 * d0
+		}
+		return s
+	}
+	m[0] = end(h[0], h[1], d[0], d[1])
+	m[n-1] = end(h[n-2], h[n-3], d[n-2], d[n-3])
+	return m
+}
+
+// 辅助：在第i段[pairs[i], pairs[i+1]]内按Hermite基函数求x处的值
+func pchipSegment(x float64, pairs [][2]float64, m []float64, i int) float64 {
+	x0, y0 := pairs[i][0], pairs[i][1]
+	x1, y1 := pairs[i+1][0], pairs[i+1][1]
+	h := x1 - x0
+	t := (x - x0) / h
+	t2, t3 := t*t, t*t*t
+	return (2*t3-3*t2+1)*y0 + (t3-2*t2+t)*h*m[i] + (-2*t3+3*t2)*y1 + (t3-t2)*h*m[i+1]
+}
+
+// pchipDensity 第i段内浓度c处的密度
+func pchipDensity(c float64, pairs [][2]float64, rowT float64, i int) float64 {
+	return pchipSegment(c, pairs, rowPCHIPSlopes(rowT, pairs), i)
+}
+
+// pchipConcentration 第i段内密度为rho的浓度：曲线在段内单调，二分求解至1e-9%
+func pchipConcentration(rho float64, pairs [][2]float64, rowT float64, i int) float64 {
+	m := rowPCHIPSlopes(rowT, pairs)
+	lo, hi := pairs[i][0], pairs[i+1][0]
+	for hi-lo > 1e-9 {
+		mid := (lo + hi) / 2
+		if pchipSegment(mid, pairs, m, i) < rho {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
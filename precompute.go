@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// 密度表为空或不足两个温度行（物性数据或配置加载异常）时查表返回的错误
+var errNoDensityTable = errors.New("未加载密度表（至少需要2个温度行），请检查-salt、-seed-tables或-config的物性数据")
+
+// checkDensityTable 启动时确认密度表可用
+func checkDensityTable() error {
+	if len(tables().sortedTemps) < 2 {
+		return errNoDensityTable
+	}
+	return nil
+}
+
+// derivedTables 当前生效的密度表及其预计算数据（排序后的温度、各温度行的PCHIP断点导数）。
+// 预计算数据在首次查表时经initOnce构建一次，之后只读，并发计算（-serve）共享同一份数据无需加锁。
+// 替换密度表须经setDensityTable整体原子换入新的derivedTables，与并发查表之间没有数据竞争。
+// initOnce随每份密度表各有一个，而非全局唯一：-salt、-seed-tables、-config及测试都会换表，
+// 全局的sync.Once只能构建一次，换表后仍会沿用旧表的预计算数据
+type derivedTables struct {
+	rows        map[float64][][2]float64 // 换入后不再修改；合并配置时另建新表
+	initOnce    sync.Once
+	sortedTemps []float64
+	pchip       map[float64][]float64 // 按温度索引
+}
+
+var derived atomic.Pointer[derivedTables]
+
+func init() {
+	setDensityTable(cobaltDensityTable)
+}
+
+// setDensityTable 替换密度表并丢弃旧的预计算数据（下次查表时重建）
+func setDensityTable(t map[float64][][2]float64) {
+	derived.Store(&derivedTables{rows: t})
+}
+
+// tables 取当前的密度表与预计算数据，首次调用时构建
+func tables() *derivedTables {
+	d := derived.Load()
+	d.initOnce.Do(d.build)
+	return d
+}
+
+// densityRows 当前生效的密度表（温度 → (浓度, 密度)行），只读
+func densityRows() map[float64][][2]float64 {
+	return derived.Load().rows
+}
+
+func (d *derivedTables) build() {
+	d.sortedTemps = make([]float64, 0, len(d.rows))
+	d.pchip = make(map[float64][]float64, len(d.rows))
+	for t, pairs := range d.rows {
+		d.sortedTemps = append(d.sortedTemps, t)
+		if len(pairs) >= 2 {
+			d.pchip[t] = pchipSlopes(pairs)
+		}
+	}
+	sort.Float64s(d.sortedTemps)
+}
+
+// rowPCHIPSlopes 温度行的PCHIP断点导数：pairs为表中rowT℃行时取预计算值（按温度键查找，O(1)），
+// rowT不是表中温度或行长度不符（临时构造的行）时现算
+func rowPCHIPSlopes(rowT float64, pairs [][2]float64) []float64 {
+	if m, ok := tables().pchip[rowT]; ok && len(m) == len(pairs) {
+		return m
+	}
+	return pchipSlopes(pairs)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// 并发计算（-serve）共享预计算数据，期间替换密度表也不产生数据竞争；需以go test -race运行才能发现竞争
+func TestCalculateConcurrent(t *testing.T) {
+	withOpts(t, func(o *calcOptions) { o.concInterp = concInterpPCHIP })
+	saved := densityRows()
+	t.Cleanup(func() { setDensityTable(saved) })
+
+	inputs := [][3]float64{{70, 1.5, 25}, {52.5, 1.55, 20}, {95, 1.42, 26}, {30, 1.6, 15}}
+	want := make([]Result, len(inputs))
+	for i, in := range inputs {
+		r, err := Calculate(in[0], in[1], in[2])
+		if err != nil {
+			t.Fatalf("T=%g rho=%g P=%g：%v", in[0], in[1], in[2], err)
+		}
+		want[i] = r
+	}
+
+	// 换入内容相同、底层数组不同的表：结果应不变，预计算数据按温度重建
+	copied := maps.Clone(saved)
+	for T, pairs := range copied {
+		copied[T] = slices.Clone(pairs)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				setDensityTable(copied)
+			} else {
+				setDensityTable(saved)
+			}
+		}
+	}()
+
+	var calc sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		calc.Add(1)
+		go func() {
+			defer calc.Done()
+			for n := 0; n < 50; n++ {
+				i := (g + n) % len(inputs)
+				got, err := Calculate(inputs[i][0], inputs[i][1], inputs[i][2])
+				if err != nil {
+					t.Errorf("T=%g：%v", inputs[i][0], err)
+					return
+				}
+				if !reflect.DeepEqual(got, want[i]) {
+					t.Errorf("T=%g：并发结果%+v，应为%+v", inputs[i][0], got, want[i])
+					return
+				}
+			}
+		}()
+	}
+	calc.Wait()
+	close(stop)
+	wg.Wait()
+}
+
+// 按温度键命中预计算的PCHIP导数（行被复制也命中）；不是表中温度的行现算
+func TestRowPCHIPSlopesByTemperature(t *testing.T) {
+	row := slices.Clone(densityRows()[60])
+	got := rowPCHIPSlopes(60, row)
+	want := tables().pchip[60]
+	if len(got) == 0 || &got[0] != &want[0] {
+		t.Errorf("60℃行未命中预计算导数")
+	}
+	if !slices.Equal(got, pchipSlopes(row)) {
+		t.Errorf("预计算导数%v与现算%v不一致", got, pchipSlopes(row))
+	}
+
+	if got := rowPCHIPSlopes(57.5, row); &got[0] == &want[0] || !slices.Equal(got, pchipSlopes(row)) {
+		t.Errorf("57.5℃（非表中温度）应现算导数，得%v", got)
+	}
+}
+
+// 密度表为空（或只有一个温度行）时启动检查与查表均返回错误，不越界panic
+func TestEmptyDensityTable(t *testing.T) {
+	saved := densityRows()
+	t.Cleanup(func() { setDensityTable(saved) })
+
+	for _, table := range []map[float64][][2]float64{nil, {}, {60: saved[60]}} {
+		setDensityTable(table)
+		if err := checkDensityTable(); !errors.Is(err, errNoDensityTable) {
+			t.Errorf("%d个温度行：checkDensityTable返回%v，应为errNoDensityTable", len(table), err)
+		}
+		if _, err := Calculate(70, 1.5, 25); err == nil {
+			t.Errorf("%d个温度行：Calculate应返回错误", len(table))
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile 一种盐溶液的物性数据：密度表与常压BPR模型
+type Profile struct {
+	Density       map[float64][][2]float64 // 温度（℃）→ 按浓度升序的 {浓度%, 密度g/cm³}
+	BPR           []bprCoefficients        // 按工作温度分带的常压BPR系数（按T升序，至少一组）
+	ReferenceBPR  bprCoefficients          // 合理性交叉校验用的独立参考拟合
+	BPRMinC       float64                  // 常压BPR关联适用浓度下限（%）
+	BPRMaxC       float64                  // 常压BPR关联适用浓度上限（%）
+	Solubility    [][2]float64             // 溶解度表 {温度℃, 饱和浓度%（无水盐计）}，可为空（不做饱和校验）
+	VaporPressure []vaporPressurePoint     // 纯水饱和蒸气压表（压力、温度均升序），可为空（用内置表）
+}
+
+// 默认物性数据（七水合硫酸钴）
+const defaultProfile = "cobalt"
+
+// 已注册的物性数据
+var profiles = map[string]Profile{}
+
+// 内置的饱和蒸气压表（物性数据未给出蒸气压表时使用）
+var defaultVaporPressureTable = VaporPressureTable
+
+// RegisterProfile 按名称注册物性数据；名称重复、数据不完整或蒸气压表乱序时panic（在init中调用）
+func RegisterProfile(name string, p Profile) {
+	if _, dup := profiles[name]; dup {
+		panic("物性数据重复注册：" + name)
+	}
+	if len(p.Density) < 2 || len(p.BPR) == 0 || p.BPRMinC >= p.BPRMaxC {
+		panic("物性数据不完整：" + name)
+	}
+	if len(p.VaporPressure) > 0 {
+		if err := validateVaporPressureTable(p.VaporPressure); err != nil {
+			panic("物性数据" + name + "：" + err.Error())
+		}
+	}
+	profiles[name] = p
+}
+
+// 辅助：已注册的名称（升序）
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateDensityTable 检查密度表：至少两个温度行，每行至少两个数据点且浓度严格升序
+// 数据点不足时插值会越界访问，须在启动时拦下并指明有问题的温度行
+func validateDensityTable(table map[float64][][2]float64) error {
+	if len(table) < 2 {
+		return fmt.Errorf("密度表至少需要2个温度行，实际%d行", len(table))
+	}
+	temps := make([]float64, 0, len(table))
+	for T := range table {
+		temps = append(temps, T)
+	}
+	sort.Float64s(temps)
+	for _, T := range temps {
+		pairs := table[T]
+		if len(pairs) < 2 {
+			return fmt.Errorf("密度表%g℃行只有%d个数据点，至少需要2个", T, len(pairs))
+		}
+		for i := 1; i < len(pairs); i++ {
+			if pairs[i][0] <= pairs[i-1][0] {
+				return fmt.Errorf("密度表%g℃行浓度未严格升序（第%d点%g%%）", T, i+1, pairs[i][0])
+			}
+		}
+	}
+	return nil
+}
+
+// validateVaporPressureTable 检查蒸气压表：至少两个数据点，压力与温度两列均严格升序
+// 查纯水沸点按相邻两点线性插值，乱序或重复的条目会静默给出错误的沸点
+func validateVaporPressureTable(table []vaporPressurePoint) error {
+	if len(table) < 2 {
+		return fmt.Errorf("蒸气压表至少需要2个数据点，实际%d个", len(table))
+	}
+	for i := 1; i < len(table); i++ {
+		prev, cur := table[i-1], table[i]
+		if cur.Pressure_kPa <= prev.Pressure_kPa {
+			return fmt.Errorf("蒸气压表压力未严格升序：第%d点%gkPa不大于第%d点%gkPa", i+1, cur.Pressure_kPa, i, prev.Pressure_kPa)
+		}
+		if cur.Temp_C <= prev.Temp_C {
+			return fmt.Errorf("蒸气压表温度未严格升序：第%d点（%gkPa）%g℃不高于第%d点（%gkPa）%g℃", i+1, cur.Pressure_kPa, cur.Temp_C, i, prev.Pressure_kPa, prev.Temp_C)
+		}
+	}
+	return nil
+}
+
+// useProfile 选用指定物性数据：装入计算所用的各表（启动时调用，计算期间只读）
+func useProfile(name string) error {
+	p, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("未知的盐溶液%q（已注册：%s）", name, strings.Join(profileNames(), ","))
+	}
+	if err := validateDensityTable(p.Density); err != nil {
+		return fmt.Errorf("物性数据%s：%w", name, err)
+	}
+	vapor := p.VaporPressure
+	if len(vapor) == 0 {
+		vapor = defaultVaporPressureTable
+	}
+	if err := validateVaporPressureTable(vapor); err != nil {
+		return fmt.Errorf("物性数据%s：%w", name, err)
+	}
+	setDensityTable(p.Density)
+	bprCoefficientTable = p.BPR
+	referenceBPRFit = p.ReferenceBPR
+	bprMinC, bprMaxC = p.BPRMinC, p.BPRMaxC
+	solubilityTable = p.Solubility
+	VaporPressureTable = vapor
+	return nil
+}
+
+func init() {
+	RegisterProfile(defaultProfile, Profile{
+		Density:      cobaltDensityTable,
+		BPR:          bprCoefficientTable,
+		ReferenceBPR: referenceBPRFit,
+		BPRMinC:      bprMinC,
+		BPRMaxC:      bprMaxC,
+		Solubility:   solubilityTable,
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 只有一个数据点的温度行：启动校验报错并指明温度，插值函数返回错误而不是越界panic
+func TestOnePointRow(t *testing.T) {
+	row := [][2]float64{{50, 1.515}}
+
+	table := map[float64][][2]float64{50: densityRows()[50], 55: row, 60: densityRows()[60]}
+	err := validateDensityTable(table)
+	if err == nil || !strings.Contains(err.Error(), "55℃行只有1个数据点") {
+		t.Errorf("validateDensityTable：%v，应指明55℃行只有1个数据点", err)
+	}
+
+	if _, err := interpDensityByConcentration(50, row, 55); err == nil {
+		t.Error("interpDensityByConcentration：单点行应返回错误")
+	}
+	if _, err := interpConcentrationByDensity(1.515, row, 55); err == nil {
+		t.Error("interpConcentrationByDensity：单点行应返回错误")
+	}
+
+	// 未经校验直接换入时，反查同样报错而不panic
+	saved := densityRows()
+	t.Cleanup(func() { setDensityTable(saved) })
+	setDensityTable(table)
+	if _, err := getConcentrationSteps(context.Background(), 52.5, 1.5); err == nil {
+		t.Error("T=52.5：相邻温度行只有1个数据点时应返回错误")
+	}
+}
+
+// 乱序的条目：错误信息指明所在的行与数据点
+func TestValidateTablesOutOfOrder(t *testing.T) {
+	table := map[float64][][2]float64{50: densityRows()[50], 60: slices.Clone(densityRows()[60])}
+	table[60][7], table[60][8] = table[60][8], table[60][7] // 51%与52%对调
+	err := validateDensityTable(table)
+	if err == nil || !strings.Contains(err.Error(), "60℃行浓度未严格升序（第9点51%）") {
+		t.Errorf("validateDensityTable：%v，应指明60℃行第9点", err)
+	}
+
+	vp := slices.Clone(VaporPressureTable)
+	vp[11], vp[12] = vp[12], vp[11] // 20kPa与25kPa对调
+	err = validateVaporPressureTable(vp)
+	if err == nil || !strings.Contains(err.Error(), "第13点20kPa不大于第12点25kPa") {
+		t.Errorf("validateVaporPressureTable：%v，应指明第13点", err)
+	}
+
+	vp = slices.Clone(VaporPressureTable)
+	vp[11].Temp_C = 65 // 20kPa的沸点高于25kPa
+	err = validateVaporPressureTable(vp)
+	if err == nil || !strings.Contains(err.Error(), "第13点（25kPa）64.5℃不高于第12点（20kPa）65℃") {
+		t.Errorf("validateVaporPressureTable：%v，应指明第13点", err)
+	}
+
+	if err := validateVaporPressureTable(VaporPressureTable); err != nil {
+		t.Errorf("内置蒸气压表：%v", err)
+	}
+}
+
+// 物性数据自带的蒸气压表乱序：注册时即panic并指明数据点
+func TestRegisterProfileBadVaporTable(t *testing.T) {
+	p := profiles[defaultProfile]
+	p.VaporPressure = slices.Clone(VaporPressureTable)
+	p.VaporPressure[11], p.VaporPressure[12] = p.VaporPressure[12], p.VaporPressure[11]
+	defer func() {
+		r := recover()
+		msg, _ := r.(string)
+		if !strings.Contains(msg, "第13点20kPa不大于第12点25kPa") {
+			t.Errorf("RegisterProfile：panic %v，应指明蒸气压表第13点", r)
+		}
+		if _, ok := profiles["bad-vapor"]; ok {
+			t.Error("蒸气压表乱序的物性数据不应注册")
+		}
+	}()
+	RegisterProfile("bad-vapor", p)
+}
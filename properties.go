@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// 摩尔质量（g/mol）
+const (
+	molarMassCoSO4        = 154.99                            // CoSO4
+	molarMassWater        = 18.015                            // H2O
+	molarMassCoSO4Hydrate = molarMassCoSO4 + 7*molarMassWater // CoSO4·7H2O，约281.10
+	molarMassCo           = 58.933                            // Co
+)
+
+// 浓度单位：质量分数（%），或摩尔浓度（mol/L）
+const (
+	concUnitMass  = "mass"
+	concUnitMolar = "molar"
+)
+
+// 辅助：校验-conc-unit取值
+func validateConcUnit(unit string) error {
+	if unit != concUnitMass && unit != concUnitMolar {
+		return fmt.Errorf("未知的浓度单位%q（可选 mass|molar）", unit)
+	}
+	return nil
+}
+
+// 浓度基准：七水合硫酸钴（密度表及计算所用），或无水CoSO4（部分化验报告所用）
+const (
+	basisHydrate   = "hydrate"
+	basisAnhydrous = "anhydrous"
+)
+
+// 辅助：校验-basis取值
+func validateBasis(basis string) error {
+	if basis != basisHydrate && basis != basisAnhydrous {
+		return fmt.Errorf("未知的浓度基准%q（可选 hydrate|anhydrous）", basis)
+	}
+	return nil
+}
+
+// ToAnhydrous 七水合物计的质量分数（%）换算为无水CoSO4计：溶质中结晶水部分计入溶剂，按摩尔质量比折算
+// 如七水合物计50% ≈ 无水计27.6%
+func ToAnhydrous(C float64) float64 {
+	return C * molarMassCoSO4 / molarMassCoSO4Hydrate
+}
+
+// ToHydrate 无水CoSO4计的质量分数（%）换算为七水合物计（ToAnhydrous的逆运算）
+func ToHydrate(C float64) float64 {
+	return C * molarMassCoSO4Hydrate / molarMassCoSO4
+}
+
+// Molarity 质量分数C（%，七水合硫酸钴计，与密度表同基准）换算为硫酸钴摩尔浓度（mol/L）
+// 每升溶液质量 = rho×1000 g，其中溶质 C/100，除以CoSO4·7H2O摩尔质量
+// 参考点：20℃、50%时密度1.569 g/cm³，0.5×1569/281.10 ≈ 2.791 mol/L
+func Molarity(C, rho float64) float64 {
+	return C / 100 * rho * 1000 / molarMassCoSO4Hydrate
+}
+
+// CobaltGPL 质量分数C（%，七水合硫酸钴计）换算为溶液中钴金属的含量（g/L）：每升溶液含七水合物 C/100×rho×1000 g，
+// 乘以七水合物中钴的质量分数（58.933/281.10 ≈ 20.97%）
+// 参考点：20℃、50%时密度1.569 g/cm³，784.5 g/L七水合物 × 0.2097 ≈ 164.5 g/L Co（= Molarity 2.791 mol/L × 58.933）
+func CobaltGPL(C, rho float64) float64 {
+	return C / 100 * rho * 1000 * molarMassCo / molarMassCoSO4Hydrate
+}
+
+// SaltMassPerM3 每立方米溶液中的七水合硫酸钴质量（kg/m³）：C/100 × rho(g/cm³) × 1000
+func SaltMassPerM3(C, rho float64) float64 {
+	return C / 100 * rho * 1000
+}
+
+// 硫酸钴在水中的溶解度：温度（℃）→ 饱和浓度（%，无水CoSO4质量分数）
+// 取自手册数据（CRC Handbook，约值），覆盖0~100℃（蒸发、结晶的工作温度均在内）；约60~70℃达到最大，之后随析出水合物类型变化而下降；
+// 有本厂实测溶解度时应以实测替换
+var solubilityTable = [][2]float64{
+	{0, 19.9}, {10, 23.0}, {20, 26.1}, {25, 27.7}, {30, 29.2}, {40, 32.3},
+	{50, 34.5}, {60, 35.5}, {70, 35.6}, {80, 34.6}, {90, 33.0}, {100, 30.5},
+}
+
+// SolubilityAt 温度T下的饱和浓度（%，七水合硫酸钴计，与密度表同基准），按溶解度表线性插值
+// T超出表范围时返回错误
+func SolubilityAt(T float64) (float64, error) {
+	n := len(solubilityTable)
+	if n < 2 || T < solubilityTable[0][0] || T > solubilityTable[n-1][0] {
+		return 0, fmt.Errorf("温度%.1f℃超出溶解度表范围", T)
+	}
+	for i := 0; i < n-1; i++ {
+		t0, s0 := solubilityTable[i][0], solubilityTable[i][1]
+		t1, s1 := solubilityTable[i+1][0], solubilityTable[i+1][1]
+		if T >= t0 && T <= t1 {
+			anhydrous := linearInterp(T, t0, s0, t1, s1)
+			return ToHydrate(anhydrous), nil
+		}
+	}
+	return 0, fmt.Errorf("溶解度插值失败")
+}
+
+// 辅助：浓度C超过温度T下的溶解度时返回警告（样品可能已部分结晶，密度读数不可靠），否则返回空串
+func solubilityWarning(T, C float64) string {
+	if solubilityTable == nil {
+		return ""
+	}
+	sat, err := SolubilityAt(T)
+	if err != nil || C <= sat {
+		return ""
+	}
+	return fmt.Sprintf("浓度%.1f%%超过%.1f℃下的溶解度%.1f%%（七水合物计），样品可能已部分结晶，密度读数不可靠，请确认无晶体析出后复测",
+		C, T, roundHalfUp(sat, 1))
+}
+
+// useSaturation 以温度T下的饱和浓度代替截断的端点浓度（-assume-saturated），返回说明该替换的提示
+// 溶解度表与密度表相互独立，只在饱和浓度不低于端点浓度（密度读数已说明浓度至少为端点值）时替换，否则报错说明二者不符。
+// 40℃以上七水合物计的溶解度（约58~64%）高于BPR关联适用区间，此时按关联外推计算沸点，并附带外推警告
+func (s *calcSteps) useSaturation(T float64) (string, error) {
+	if solubilityTable == nil {
+		return "", fmt.Errorf("当前物性数据没有溶解度表，无法按饱和料液处理（-assume-saturated）")
+	}
+	sat, err := SolubilityAt(T)
+	if err != nil {
+		return "", fmt.Errorf("按饱和料液处理：%w", err)
+	}
+	clampC := s.C
+	if sat < clampC {
+		return "", fmt.Errorf("按饱和料液处理：%.1f℃下的溶解度%.1f%%（七水合物计）低于密度读数对应的端点浓度%.1f%%，饱和假设与读数矛盾，请确认样品是否含晶体或复测密度",
+			T, roundHalfUp(sat, 1), clampC)
+	}
+	s.exactC = sat
+	s.saturated = true
+	s.C = roundHalfUp(sat, 1)
+	return fmt.Sprintf("密度超出表上限，按饱和料液（-assume-saturated）取%.1f℃下的溶解度%.1f%%（七水合物计）代替端点浓度%.1f%%",
+		T, s.C, clampC), nil
+}
+
+// VaporPressureAt 纯水在温度T（℃）下的饱和蒸气压（kPa），按蒸气压表反向插值（方式同-vapor）
+func VaporPressureAt(T float64) (float64, error) {
+	n := len(VaporPressureTable)
+	for i := 0; i < n-1; i++ {
+		t0, t1 := VaporPressureTable[i].Temp_C, VaporPressureTable[i+1].Temp_C
+		if T >= t0 && T <= t1 {
+			return vaporPressureBetween(T, VaporPressureTable[i].Pressure_kPa, t0, VaporPressureTable[i+1].Pressure_kPa, t1), nil
+		}
+	}
+	return 0, fmt.Errorf("温度%.1f℃超出蒸气压表范围", T)
+}
+
+// WaterActivity 溶液的水活度：溶液在压力P（kPa，绝压）下于tl（℃）沸腾，
+// 即其水蒸气分压等于P，故 aw = P / 纯水在tl下的饱和蒸气压（与沸点计算使用同一蒸气压表，结果与本工具的BPR模型自洽）
+func WaterActivity(P, tl float64) (float64, error) {
+	p0, err := VaporPressureAt(tl)
+	if err != nil {
+		return 0, err
+	}
+	return P / p0, nil
+}
+
+// 比热容（kJ/(kg·K)）：水取40~80℃的平均值；无水CoSO4取固体比热（约103 J/(mol·K) ÷ 154.99 g/mol）
+const (
+	cpWater = 4.18
+	cpCoSO4 = 0.66
+)
+
+// SpecificHeat 浓度C（%，七水合硫酸钴计）溶液的近似比热容（kJ/(kg·K)），按无水CoSO4与水的质量加和：
+// cp = w·cpCoSO4 + (1−w)·cpWater，w为无水计质量分数（结晶水计入水）；不计稀释热，浓溶液误差约±10%
+// 参考点：50%（无水计27.6%）时约3.21 kJ/(kg·K)
+func SpecificHeat(C float64) float64 {
+	w := ToAnhydrous(C) / 100
+	return w*cpCoSO4 + (1-w)*cpWater
+}
+
+// TimeToBoil 质量massKg（kg）、浓度C的溶液在净加热功率heatKW（kW）下由当前温度T升温至沸点tl所需的时间（分钟）：
+// t = m·cp·(tl − T) / Q，比热容按SpecificHeat；不计热损失与升温中的蒸发，为排产用粗估。T不低于tl时返回0
+func TimeToBoil(C, massKg, heatKW, T, tl float64) float64 {
+	if T >= tl {
+		return 0
+	}
+	return massKg * SpecificHeat(C) * (tl - T) / heatKW / 60
+}
+
+// 沸点升高关系所用常数
+const (
+	gasConstant        = 8.314   // J/(mol·K)
+	vanHoffFactorCoSO4 = 2       // CoSO4完全电离为Co²⁺与SO4²⁻
+	waterHvap100       = 40.65e3 // 水在100℃的摩尔汽化焓（J/mol）
+	waterHvapSlope     = -44.5   // 汽化焓随温度的变化（J/(mol·K)，25℃时约43.99 kJ/mol）
+)
+
+// Molality 质量分数C（%，七水合硫酸钴计）对应的CoSO4质量摩尔浓度（mol/kg水）：结晶水计入溶剂
+// 参考点：50%时每100g溶液含CoSO4 0.1779 mol、水72.4 g，约2.46 mol/kg
+func Molality(C float64) float64 {
+	n := C / molarMassCoSO4Hydrate
+	water := 100 - C + n*7*molarMassWater
+	return n / (water / 1000)
+}
+
+// EbullioscopicConstant 纯水在沸点tw（℃）下的沸点升高常数Kb = R·Tb²·Mw/ΔHvap（K·kg/mol）；100℃时约0.513
+// 负压下沸点低，Kb随之减小（40℃约0.34）
+func EbullioscopicConstant(tw float64) float64 {
+	Tb := tw + 273.15
+	hvap := waterHvap100 + waterHvapSlope*(tw-100)
+	return gasConstant * Tb * Tb * molarMassWater / 1000 / hvap
+}
+
+// OsmoticCoefficient 由沸点升高关系 ΔTb = Kb·m·i·φ 反推渗透系数φ：bpr为沸点升高（℃），tw为纯水沸点（℃）
+// 理想稀溶液φ=1；本工具的BPR为高浓度经验关联，φ明显大于1反映强电解质浓溶液的非理想性
+func OsmoticCoefficient(C, bpr, tw float64) float64 {
+	m := Molality(C)
+	if m <= 0 {
+		return 0
+	}
+	return bpr / (EbullioscopicConstant(tw) * m * vanHoffFactorCoSO4)
+}
+
+// 水的Antoine方程常数（log10(p/mmHg) = A − B/(C + t/℃)，适用1~100℃）
+const (
+	antoineA = 8.07131
+	antoineB = 1730.63
+	antoineC = 233.426
+
+	mmHgPerKPa = 7.50062
+)
+
+// AntoineWaterBoilingPoint 按Antoine方程求纯水在压力P（kPa，绝压）下的沸点（℃），与蒸气压表相互独立
+func AntoineWaterBoilingPoint(P float64) (float64, error) {
+	if P <= 0 {
+		return 0, fmt.Errorf("压力须为正，当前%gkPa", P)
+	}
+	t := antoineB/(antoineA-math.Log10(P*mmHgPerKPa)) - antoineC
+	if t < 1 || t > 100 {
+		return 0, fmt.Errorf("压力%gkPa对应的沸点%.1f℃超出Antoine方程适用范围（1~100℃）", P, t)
+	}
+	return t, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// 辅助：在测试期间临时修改opts，结束时恢复
+func withOpts(t *testing.T, set func(o *calcOptions)) {
+	t.Helper()
+	saved := opts
+	t.Cleanup(func() { opts = saved })
+	set(&opts)
+}
+
+// -assume-saturated：溶解度不低于端点浓度时替换；超出BPR适用区间时外推并附带警告，低于端点浓度时报错
+func TestAssumeSaturated(t *testing.T) {
+	withOpts(t, func(o *calcOptions) { o.assumeSaturated = true })
+
+	cases := []struct {
+		T, C   float64
+		extrap bool
+	}{
+		{30, 53.0, false}, // 溶解度约53.0%，高于端点52.0%且在45~53%内
+		{60, 64.4, true},  // 蒸发、结晶的常见工作温度：溶解度64.4%，BPR外推
+		{80, 62.8, true},
+	}
+	for _, c := range cases {
+		s, err := calculateSteps(c.T, 1.62, 20)
+		if err != nil {
+			t.Errorf("%g℃：%v", c.T, err)
+			continue
+		}
+		if s.C != c.C || !s.saturated {
+			t.Errorf("%g℃：C=%v saturated=%v，应为溶解度%v并标记饱和", c.T, s.C, s.saturated, c.C)
+		}
+		joined := strings.Join(s.warnings, "\n")
+		if !strings.Contains(joined, "按饱和料液") {
+			t.Errorf("%g℃：警告%q中缺少饱和替换的说明", c.T, s.warnings)
+		}
+		if got := strings.Contains(joined, "结果为外推值"); got != c.extrap {
+			t.Errorf("%g℃：外推警告=%v，应为%v", c.T, got, c.extrap)
+		}
+		if strings.Contains(joined, "取端点值") {
+			t.Errorf("%g℃：已按饱和处理，不应再提示取端点值：%q", c.T, s.warnings)
+		}
+	}
+
+	// 60℃的结果经Calculate照常给出（常压沸点同样按外推计算）
+	res, err := Calculate(60, 1.62, 20)
+	if err != nil {
+		t.Fatalf("60℃：%v", err)
+	}
+	if res.C != 64.4 || res.TlAtm <= 100 {
+		t.Errorf("60℃：C=%v TlAtm=%v", res.C, res.TlAtm)
+	}
+
+	// 20℃溶解度47.3%低于端点52.0%：饱和假设与读数矛盾
+	if _, err := calculateSteps(20, 1.62, 20); err == nil || !strings.Contains(err.Error(), "低于密度读数对应的端点浓度") {
+		t.Errorf("20℃：错误%v，应说明溶解度低于端点浓度", err)
+	}
+}
+
+// 摩尔浓度：C% × ρ × 1000 / M(CoSO4·7H2O)，M = 154.99 + 7×18.015 = 281.095 g/mol
+func TestMolarity(t *testing.T) {
+	cases := []struct{ C, rho, want float64 }{
+		{50, 1.569, 2.79087}, // 784.5 g/L 七水盐
+		{45, 1.440, 2.30527}, // 648.0 g/L
+		{52, 1.418, 2.62317}, // 737.36 g/L
+	}
+	for _, c := range cases {
+		if got := Molarity(c.C, c.rho); math.Abs(got-c.want) > 1e-4 {
+			t.Errorf("Molarity(%g, %g) = %.5f，应为%.5f", c.C, c.rho, got, c.want)
+		}
+	}
+}
+
+// 钴含量：C% × ρ × 1000 × 58.933 / 281.095，单位g/L Co
+func TestCobaltGPL(t *testing.T) {
+	cases := []struct{ C, rho, want float64 }{
+		{50, 1.569, 164.474},
+		{45, 1.440, 135.857},
+		{52, 1.418, 154.591},
+	}
+	for _, c := range cases {
+		if got := CobaltGPL(c.C, c.rho); math.Abs(got-c.want) > 1e-3 {
+			t.Errorf("CobaltGPL(%g, %g) = %.3f，应为%.3f", c.C, c.rho, got, c.want)
+		}
+	}
+}
+
+// 升温时间：t = m·cp·(tl − T)/Q；50%（无水计27.57%）cp = 0.2757×0.66 + 0.7243×4.18 ≈ 3.2096 kJ/(kg·K)
+func TestTimeToBoil(t *testing.T) {
+	cases := []struct{ C, mass, heat, T, tl, want float64 }{
+		{50, 1000, 100, 60, 72, 6.41914}, // 1000×3.2096×12/100/60
+		{50, 2000, 100, 60, 72, 12.8383}, // 质量加倍，时间加倍
+		{50, 1000, 100, 72, 72, 0},       // 已达沸点
+		{50, 1000, 100, 75, 72, 0},       // 高于沸点
+	}
+	for _, c := range cases {
+		if got := TimeToBoil(c.C, c.mass, c.heat, c.T, c.tl); math.Abs(got-c.want) > 1e-4 {
+			t.Errorf("TimeToBoil(%g, %g, %g, %g, %g) = %.5f，应为%.5f", c.C, c.mass, c.heat, c.T, c.tl, got, c.want)
+		}
+	}
+}
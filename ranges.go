@@ -0,0 +1,129 @@
+package main
+
+import "fmt"
+
+// Range 闭区间[Min, Max]
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Ranges 各输入的适用范围，均由当前数据表与选项算出（界面展示、启动横幅与输入校验共用）
+type Ranges struct {
+	Temperature   Range             `json:"temperature_c"`     // 密度表的温度范围
+	Pressure      Range             `json:"pressure_kpa"`      // 工艺压力（绝压；-deep-vacuum时下限为蒸气压表首点）
+	Concentration Range             `json:"concentration_pct"` // BPR关联的适用浓度
+	Density       map[float64]Range `json:"density_g_cm3"`     // 各温度行上高浓度区对应的密度（自适用浓度下限至该行最高浓度）
+	DensityAll    Range             `json:"density_all_g_cm3"` // 各温度行密度范围的并集
+}
+
+// pressureRange 当前选项下允许的工艺压力范围（kPa，绝压）
+func pressureRange() Range {
+	r := Range{Min: minProcessPressure, Max: maxProcessPressure}
+	if opts.deepVacuum {
+		r.Min = VaporPressureTable[0].Pressure_kPa
+	}
+	return r
+}
+
+// SupportedRanges 列出所有输入的适用范围
+func SupportedRanges() Ranges {
+	temps := getSortedDensityTemps()
+	r := Ranges{
+		Pressure:      pressureRange(),
+		Concentration: Range{Min: bprMinC, Max: bprMaxC},
+		Density:       make(map[float64]Range, len(temps)),
+	}
+	if len(temps) == 0 { // 未加载密度表（checkDensityTable在启动时已报错）
+		return r
+	}
+	r.Temperature = Range{Min: temps[0], Max: temps[len(temps)-1]}
+	for i, T := range temps {
+		pairs := densityRows()[T]
+		last := pairs[len(pairs)-1]
+		lo := last[1]
+		if c := max(bprMinC, pairs[0][0]); c < last[0] {
+			if rho, err := interpDensityByConcentration(c, pairs, T); err == nil {
+				lo = rho
+			}
+		}
+		row := Range{Min: lo, Max: last[1]}
+		r.Density[T] = row
+		if i == 0 {
+			r.DensityAll = row
+			continue
+		}
+		r.DensityAll.Min = min(r.DensityAll.Min, row.Min)
+		r.DensityAll.Max = max(r.DensityAll.Max, row.Max)
+	}
+	return r
+}
+
+// ConcentrationRangeAt 温度T下可计算的浓度范围（%）：BPR适用区间与相邻两温度行共同覆盖的浓度取交集
+func ConcentrationRangeAt(T float64) (Range, error) {
+	tLeft, tRight, err := findAdjacentTemps(T)
+	if err != nil {
+		return Range{}, err
+	}
+	pairsLeft, pairsRight := densityRows()[tLeft], densityRows()[tRight]
+	r := Range{
+		Min: max(bprMinC, pairsLeft[0][0], pairsRight[0][0]),
+		Max: min(bprMaxC, pairsLeft[len(pairsLeft)-1][0], pairsRight[len(pairsRight)-1][0]),
+	}
+	if r.Min > r.Max {
+		return Range{}, fmt.Errorf("温度%.1f℃下密度表不覆盖BPR适用浓度%g%%~%g%%", T, bprMinC, bprMaxC)
+	}
+	return r, nil
+}
+
+// DensityRangeAt 温度T下可计算的密度范围（g/cm³）：ConcentrationRangeAt两端浓度在T下的密度
+func DensityRangeAt(T float64) (Range, error) {
+	cr, err := ConcentrationRangeAt(T)
+	if err != nil {
+		return Range{}, err
+	}
+	lo, err := DensityFor(T, cr.Min)
+	if err != nil {
+		return Range{}, err
+	}
+	hi, err := DensityFor(T, cr.Max)
+	if err != nil {
+		return Range{}, err
+	}
+	return Range{Min: roundHalfUp(lo, 3), Max: roundHalfUp(hi, 3)}, nil
+}
+
+// checkTemperature 温度须在密度表范围内
+func checkTemperature(T float64) error {
+	_, _, err := findAdjacentTemps(T)
+	return err
+}
+
+// checkPressure 压力（kPa，绝压）须在当前允许的工艺压力范围内
+func checkPressure(P float64) error {
+	if r := pressureRange(); P < r.Min || P > r.Max {
+		return fmt.Errorf("压力仅支持%g~%gkPa（绝压），当前P=%.1fkPa", r.Min, r.Max, P)
+	}
+	return nil
+}
+
+// checkDensity 温度T下的密度不得低于BPR适用浓度下限对应的密度（-allow-extrapolate时不限）；
+// 高于上限时按表端点截断并给出警告，不在此拦截。接近纯水的读数先按nearWaterCheck报告（多为取错样品）
+func checkDensity(T, rho float64) error {
+	if err := nearWaterCheck(T, rho); err != nil {
+		return err
+	}
+	if opts.allowExtrapolate {
+		return nil
+	}
+	r, err := DensityRangeAt(T)
+	if err != nil {
+		return err
+	}
+	if rho < r.Min {
+		cr, _ := ConcentrationRangeAt(T)
+		return fmt.Errorf("%.1f℃下密度应在%.3f~%.3f g/cm³（浓度%g%%~%g%%），当前%.3f g/cm³浓度过低",
+			T, r.Min, r.Max, cr.Min, cr.Max, rho)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// 接近纯水的读数先报"测错样品"，不被一般的范围错误掩盖；普通的过低读数报适用范围
+func TestCheckDensity(t *testing.T) {
+	cases := []struct {
+		T, rho float64
+		want   string
+	}{
+		{60, 1.0, "接近纯水"},
+		{20, 1.002, "接近纯水"},
+		{60, 1.3, "浓度过低"},
+		{60, 1.5, ""},
+		{60, 1.6, ""}, // 高于上限：计算时按端点截断，不在此拦截
+	}
+	for _, c := range cases {
+		err := checkDensity(c.T, c.rho)
+		if c.want == "" {
+			if err != nil {
+				t.Errorf("T=%g rho=%g：意外错误%v", c.T, c.rho, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("T=%g rho=%g：错误%v，应含%q", c.T, c.rho, err, c.want)
+		}
+	}
+}
+
+// 适用范围取自当前数据表：温度为密度表首末行，各行密度自45%（BPR下限）至该行最高浓度；-deep-vacuum放宽压力下限
+func TestSupportedRanges(t *testing.T) {
+	r := SupportedRanges()
+	if r.Temperature != (Range{20, 100}) || r.Pressure != (Range{8, 28}) || r.Concentration != (Range{45, 53}) {
+		t.Errorf("温度%v 压力%v 浓度%v，应为20~100℃、8~28kPa、45~53%%", r.Temperature, r.Pressure, r.Concentration)
+	}
+	for T, want := range map[float64]Range{20: {1.497, 1.599}, 100: {1.330, 1.418}} {
+		if r.Density[T] != want {
+			t.Errorf("%g℃行密度%v，应为%v", T, r.Density[T], want)
+		}
+	}
+	for T, row := range r.Density {
+		if row.Min < r.DensityAll.Min || row.Max > r.DensityAll.Max {
+			t.Errorf("%g℃行密度%v超出并集%v", T, row, r.DensityAll)
+		}
+	}
+
+	withOpts(t, func(o *calcOptions) { o.deepVacuum = true })
+	if got := SupportedRanges().Pressure; got != (Range{1, 28}) {
+		t.Errorf("-deep-vacuum：压力%v，应为1~28kPa（蒸气压表首点起）", got)
+	}
+}
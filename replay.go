@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// replayRecord DCS导出的班次记录（JSON行）中的一条：时间戳原样保留，数值字段同-input-json
+type replayRecord struct {
+	TS json.RawMessage `json:"ts"`
+	jsonRequest
+}
+
+// 辅助：时间戳按原文输出；JSON字符串去掉引号，数字（如Unix时间）保持原样
+func replayTimestamp(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// runReplay 回放一个班次的测量记录（每行一个JSON对象，如 {"ts":"2026-10-17T08:00:00+08:00","T":60,"rho":1.45,"P":20}），
+// 按输入顺序输出 ts,T,rho,P,C,tw,bpr,tl,error 的时间序列CSV；空行跳过，单条记录无法解析或计算失败时写入error列
+func runReplay(in io.Reader, out io.Writer, cfg batchConfig) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"ts", "T", "rho", "P", "C", "tw", "bpr", "tl", "error"}); err != nil {
+		return err
+	}
+	num := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	for line := 1; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var rec replayRecord
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&rec)
+		if err != nil {
+			err = fmt.Errorf("格式错误：%w", err)
+		}
+
+		P := rec.P
+		if P == nil && err == nil && cfg.hasDefaultP {
+			P = &cfg.defaultP // 缺P的记录取-P，输出实际使用的压力
+		}
+		fields := []string{replayTimestamp(rec.TS), num(rec.T), num(rec.Rho), num(P), "", "", "", "", ""}
+		if err == nil {
+			var res Result
+			if res, err = calculateJSONRequest(rec.jsonRequest, cfg); err == nil {
+				fields[4] = strconv.FormatFloat(res.C, 'f', 1, 64)
+				fields[5] = strconv.FormatFloat(res.Tw, 'f', 1, 64)
+				fields[6] = strconv.FormatFloat(res.BPR, 'f', 1, 64)
+				fields[7] = strconv.FormatFloat(res.Tl, 'f', 1, 64)
+			}
+		}
+		if err != nil {
+			fields[8] = fmt.Sprintf("第%d行：%v", line, err)
+		}
+		if err := w.Write(fields); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// 执行-replay：读取JSON行记录文件（- 表示标准输入）
+func runReplayFile(path string, cfg batchConfig) error {
+	return withInputFile(path, func(in io.Reader) error { return runReplay(in, os.Stdout, cfg) })
+}
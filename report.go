@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// fillReportProperties 补齐报告所需的全部派生物性（摩尔浓度、含盐量、水活度）
+func fillReportProperties(res *Result) error {
+	res.Molarity = Molarity(res.C, res.Rho)
+	res.SaltMassPerM3 = SaltMassPerM3(res.C, res.Rho)
+	aw, err := WaterActivity(res.P, res.Tl)
+	if err != nil {
+		return err
+	}
+	res.WaterActivity = aw
+	return nil
+}
+
+// writeReport 以带标签的文本块输出样品的完整物性报告（用于化验报告存档）
+func writeReport(w io.Writer, res Result, pressure string) error {
+	lines := []string{
+		"=== 样品物性报告 ===",
+		fmt.Sprintf("实测温度：        %.1f ℃", res.T),
+		fmt.Sprintf("实测密度：        %.3f g/cm³", res.Rho),
+		fmt.Sprintf("工艺压力：        %s", pressure),
+		fmt.Sprintf("浓度：            %.1f %%（七水合硫酸钴计）", res.C),
+		fmt.Sprintf("摩尔浓度：        %.3f mol/L", res.Molarity),
+		fmt.Sprintf("每m³含七水合物：  %.0f kg", res.SaltMassPerM3),
+		fmt.Sprintf("纯水沸点：        %.1f ℃", res.Tw),
+		fmt.Sprintf("BPR：             %.1f ℃", res.BPR),
+		fmt.Sprintf("溶液沸点：        %.1f ℃", res.Tl),
+		fmt.Sprintf("水活度：          %.3f", res.WaterActivity),
+		fmt.Sprintf("渗透系数φ：       %.3f", res.OsmoticCoefficient),
+	}
+	if res.BPRBand > 0 {
+		lines = append(lines, fmt.Sprintf("BPR不确定度：     ±%.1f ℃（约95%%）", res.BPRBand))
+	}
+	for _, warning := range res.Warnings {
+		lines = append(lines, "警告："+warning)
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// -safe-mode的交叉校验容差
+// 浓度：与往返校验同一容差；纯水沸点：本工具的蒸气压表较Antoine方程在8~28kPa普遍低0.4~0.5℃，
+// 表中相邻点之间线性插值再偏低至多约0.2℃，容差取1.0℃，只拦下表数据或插值的明显错误
+const (
+	safeModeConcTol = inversionTolerance
+	safeModeTwTol   = 1.0
+)
+
+// directConcentration 直接按正向模型（双线性：行内按浓度、行间按温度）在共有浓度区间内二分求解
+// DensityAtTempForConcentration(T, c) = rho，与反查流程（先换算相邻温度的等效密度）互为独立算法
+func directConcentration(T, rho float64) (float64, error) {
+	tLeft, tRight, err := findAdjacentTemps(T)
+	if err != nil {
+		return 0, err
+	}
+	pairsLeft, pairsRight := densityRows()[tLeft], densityRows()[tRight]
+	lo := math.Max(pairsLeft[0][0], pairsRight[0][0])
+	hi := math.Min(pairsLeft[len(pairsLeft)-1][0], pairsRight[len(pairsRight)-1][0])
+	return solveConcentrationBetween(T, rho, lo, hi), nil
+}
+
+// safeModeConcentrationCheck 反查浓度c与直接求解的结果相差超过容差时返回错误
+func safeModeConcentrationCheck(T, rho, c float64) error {
+	direct, err := directConcentration(T, rho)
+	if err != nil {
+		return err
+	}
+	if d := c - direct; math.Abs(d) > safeModeConcTol {
+		return fmt.Errorf("安全模式：反查浓度%.3f%%与双线性直接求解%.3f%%相差%+.3f%%，超出容差±%.2f%%", c, direct, d, safeModeConcTol)
+	}
+	return nil
+}
+
+// safeModeBoilingPointCheck 查表所得纯水沸点tw与Antoine方程相差超过容差时返回错误
+func safeModeBoilingPointCheck(P, tw float64) error {
+	antoine, err := AntoineWaterBoilingPoint(P)
+	if err != nil {
+		return err
+	}
+	if d := tw - antoine; math.Abs(d) > safeModeTwTol {
+		return fmt.Errorf("安全模式：%.1fkPa下查表纯水沸点%.1f℃与Antoine方程%.2f℃相差%+.2f℃，超出容差±%.1f℃", P, tw, antoine, d, safeModeTwTol)
+	}
+	return nil
+}
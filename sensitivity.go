@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sensitivities 溶液沸点tl对各输入的解析偏导：∂tl/∂T（℃/℃）、∂tl/∂rho（℃ 每 g/cm³）、∂tl/∂P（℃/kPa）
+// 按链式法则沿计算流程逐步求导，不计结果取整；各插值在断点处不可导，恰在断点上时取右侧区间的斜率。
+// 密度超出表范围（浓度取端点值）时浓度不随密度变化，∂tl/∂T与∂tl/∂rho为0；常压BPR取下限或K限幅时同理
+func Sensitivities(T, rho, P float64) (dT, dRho, dP float64, err error) {
+	s, err := calculateSteps(T, rho, P)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// 浓度对温度、密度的偏导：由 rho = D(T, C) 隐函数求导
+	var dCdT, dCdRho float64
+	if len(s.clamps) == 0 {
+		dDdC := (1-tempWeight(T, s.tLeft, s.tRight))*rowDensitySlope(s.exactC, densityRows()[s.tLeft], s.tLeft) +
+			tempWeight(T, s.tLeft, s.tRight)*rowDensitySlope(s.exactC, densityRows()[s.tRight], s.tRight)
+		if dDdC <= 0 {
+			return 0, 0, 0, fmt.Errorf("浓度%.1f%%处密度随浓度的斜率不为正，无法求导", s.exactC)
+		}
+		rhoL, err := interpDensityByConcentration(s.exactC, densityRows()[s.tLeft], s.tLeft)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		rhoR, err := interpDensityByConcentration(s.exactC, densityRows()[s.tRight], s.tRight)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		dDdT := (rhoR - rhoL) / (s.tRight - s.tLeft)
+		dCdRho = 1 / dDdC
+		dCdT = -dDdT / dDdC
+	}
+
+	// 溶液沸点对浓度、纯水沸点的偏导：BPR系数按工作温度tOp选取，tOp = tw + K(tw)×bprAtm(C, tOp)隐函数求导，
+	// 再代入 tl = tw + 校正(K×bprAtm)
+	tOp := bprOperatingTemp(s.bprAtm, s.K, s.tw)
+	slope, intercept := bprCoefficientsAt(tOp)
+	dSlope, dIntercept := bprCoefficientsSlopeAt(tOp)
+	bprAtm := slope*s.C + intercept
+	dBPRdC, dBPRdTop := slope, dSlope*s.C+dIntercept
+	if bprAtm < bprFloorAt(tOp) {
+		dBPRdC, dBPRdTop = 0, 0
+	}
+	K, raw := pressureCorrection(s.tw)
+	dKdTw := kCurveSlope(s.tw)
+	if K != raw {
+		dKdTw = 0
+	}
+	scale := bprCalibration.scale
+	implicit := 1 - K*dBPRdTop
+	dTopdTw := (1 + bprAtm*dKdTw) / implicit
+	dTldC := scale * K * dBPRdC / implicit
+	dTldTw := 1 + scale*(bprAtm*dKdTw+K*dBPRdTop*dTopdTw)
+
+	return dTldC * dCdT, dTldC * dCdRho, dTldTw * waterBoilingPointSlope(P), nil
+}
+
+// 辅助：温度插值中右行的权重
+func tempWeight(T, tLeft, tRight float64) float64 {
+	return (T - tLeft) / (tRight - tLeft)
+}
+
+// rowDensitySlope 温度行内浓度c处的dρ/dC（按当前行内插值方式；断点处取右侧区间）
+func rowDensitySlope(c float64, pairs [][2]float64, rowT float64) float64 {
+	n := len(pairs)
+	i := sort.Search(n, func(i int) bool { return pairs[i][0] > c }) - 1
+	i = min(max(i, 0), n-2)
+	c0, rho0 := pairs[i][0], pairs[i][1]
+	c1, rho1 := pairs[i+1][0], pairs[i+1][1]
+	if opts.concInterp != concInterpPCHIP {
+		return (rho1 - rho0) / (c1 - c0)
+	}
+	m := rowPCHIPSlopes(rowT, pairs)
+	h := c1 - c0
+	t := (c - c0) / h
+	return ((6*t*t-6*t)*rho0+(-6*t*t+6*t)*rho1)/h + (3*t*t-4*t+1)*m[i] + (3*t*t-2*t)*m[i+1]
+}
+
+// bprCoefficientsSlopeAt BPR系数（斜率、截距）对温度的导数：带间为线性插值，两端外为0；与bprCoefficientsAt同样折算到100℃参考
+func bprCoefficientsSlopeAt(T float64) (dSlope, dIntercept float64) {
+	for i := 0; i < len(bprCoefficientTable)-1; i++ {
+		b0, b1 := bprCoefficientTable[i], bprCoefficientTable[i+1]
+		if T >= b0.T && T < b1.T {
+			scale := atmReferenceScale()
+			return scale * (b1.slope - b0.slope) / (b1.T - b0.T), scale * (b1.intercept - b0.intercept) / (b1.T - b0.T)
+		}
+	}
+	return 0, 0
+}
+
+// waterBoilingPointSlope 纯水沸点对压力的导数dtw/dP（℃/kPa），取蒸气压表中P所在区间（断点处取右侧）
+// -vapor loglinear时温度对ln P线性，导数为区间斜率除以P
+func waterBoilingPointSlope(P float64) float64 {
+	n := len(VaporPressureTable)
+	for i := 0; i < n-1; i++ {
+		p0, p1 := VaporPressureTable[i].Pressure_kPa, VaporPressureTable[i+1].Pressure_kPa
+		dt := VaporPressureTable[i+1].Temp_C - VaporPressureTable[i].Temp_C
+		if P >= p0 && (P < p1 || i == n-2) {
+			if opts.vapor == vaporLogLinear {
+				return dt / (math.Log(p1) - math.Log(p0)) / P
+			}
+			return dt / (p1 - p0)
+		}
+	}
+	return 0
+}
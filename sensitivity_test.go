@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// 解析偏导与不取整连续模型的中心差分一致：tl = tw(P) + K(tw)×(斜率×C(T, rho) + 截距)
+// 取65℃（60、70℃两行之间）、1.50g/cm³、17kPa（蒸气压表15~20kPa区间内），各插值均不在断点上。
+// 解析偏导在取整后的tw、C处求值，与连续模型相差约5e-5（相对），按1e-4比较
+func TestSensitivitiesFiniteDifference(t *testing.T) {
+	model := func(T, rho, P float64) float64 {
+		// 浓度：二分解 DensityFor(T, C) = rho（正向流程按表精度取整等效密度，差分时会有台阶）
+		lo, hi := 40.0, 52.0
+		for range 60 {
+			mid := (lo + hi) / 2
+			d, err := DensityFor(T, mid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d < rho {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		C := (lo + hi) / 2
+		tw := vaporTempBetween(P, 15, 53.6, 20, 59.7)
+		K, _ := pressureCorrection(tw)
+		slope, intercept := bprCoefficientsAt(tw)
+		return tw + K*(slope*C+intercept)
+	}
+	const T, rho, P = 65.0, 1.50, 17.0
+
+	dT, dRho, dP, err := Sensitivities(T, rho, P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		got, want float64
+	}{
+		{"∂tl/∂T", dT, (model(T+1e-3, rho, P) - model(T-1e-3, rho, P)) / 2e-3},
+		{"∂tl/∂rho", dRho, (model(T, rho+1e-5, P) - model(T, rho-1e-5, P)) / 2e-5},
+		{"∂tl/∂P", dP, (model(T, rho, P+1e-3) - model(T, rho, P-1e-3)) / 2e-3},
+	}
+	for _, c := range cases {
+		if c.want == 0 || math.Abs(c.got-c.want) > 1e-4*math.Abs(c.want) {
+			t.Errorf("%s = %v，中心差分%v", c.name, c.got, c.want)
+		}
+	}
+}
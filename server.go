@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// 服务模式超时设置：单次计算耗时极短，2秒足以覆盖正常请求
+const (
+	requestTimeout  = 2 * time.Second
+	shutdownTimeout = 5 * time.Second
+)
+
+// serverConfig 服务模式的设置
+type serverConfig struct {
+	pressureType string  // 请求中压力的类型（absolute|gauge）
+	atm          float64 // 当地大气压（kPa）
+
+	cache *resultCache // 计算结果缓存
+	hub   *streamHub   // -continuous时的/stream订阅者，未开启为nil
+}
+
+// 辅助：写JSON响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// 辅助：读取必填的数值查询参数
+func queryFloat(r *http.Request, name string) (float64, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return 0, fmt.Errorf("缺少参数%s", name)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("参数%s=%q不是数字", name, s)
+	}
+	return v, nil
+}
+
+// GET /calculate?T=..&rho=..&P=.. ：返回Result的JSON，输入或计算错误返回400和{"error": ...}
+func (cfg serverConfig) handleCalculate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, jsonError{Error: "仅支持GET"})
+		return
+	}
+	var vals [3]float64
+	for i, name := range []string{"T", "rho", "P"} {
+		v, err := queryFloat(r, name)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, jsonError{Error: err.Error()})
+			return
+		}
+		vals[i] = v
+	}
+	res, err := cfg.calculate(r.Context(), vals[0], vals[1], vals[2])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, jsonError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// calculate 服务模式的计算核心（/calculate与/stream共用）：压力P按cfg.pressureType换算为绝压，结果经缓存
+func (cfg serverConfig) calculate(ctx context.Context, T, rho, P float64) (Result, error) {
+	PAbs, err := toAbsolutePressure(P, cfg.pressureType, cfg.atm)
+	if err != nil {
+		return Result{}, err
+	}
+	key := newCacheKey(T, rho, PAbs)
+	res, ok := cfg.cache.get(key)
+	if !ok {
+		if res, err = CalculateContext(ctx, T, rho, PAbs); err != nil {
+			return Result{}, err
+		}
+		cfg.cache.put(key, res)
+	}
+	if cfg.pressureType == pressureGauge {
+		res.PGauge = P
+	}
+	res.addWarning(pressurePrecisionWarning(P))
+	return res, nil
+}
+
+// GET /metrics ：Prometheus文本格式的缓存计数
+func (cfg serverConfig) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	hits, misses, entries := cfg.cache.stats()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprintf(w, "# HELP bpr_cache_hits_total 命中缓存的计算请求数\n# TYPE bpr_cache_hits_total counter\nbpr_cache_hits_total %d\n", hits)
+	fmt.Fprintf(w, "# HELP bpr_cache_misses_total 未命中缓存的计算请求数\n# TYPE bpr_cache_misses_total counter\nbpr_cache_misses_total %d\n", misses)
+	fmt.Fprintf(w, "# HELP bpr_cache_entries 当前缓存条目数\n# TYPE bpr_cache_entries gauge\nbpr_cache_entries %d\n", entries)
+}
+
+// 服务模式的路由；每个请求限时requestTimeout（/stream为长连接，不受此限）
+func (cfg serverConfig) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/calculate", cfg.handleCalculate)
+	mux.HandleFunc("/metrics", cfg.handleMetrics)
+	timed := http.TimeoutHandler(mux, requestTimeout, `{"error":"请求超时"}`)
+	if cfg.hub == nil {
+		return timed
+	}
+	root := http.NewServeMux()
+	root.HandleFunc("/stream", cfg.handleStream)
+	root.Handle("/", timed)
+	return root
+}
+
+// serve 在ln上提供服务，直到ctx取消后优雅关闭（等待处理中的请求完成，最长shutdownTimeout）
+func serve(ctx context.Context, ln net.Listener, h http.Handler) error {
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: requestTimeout,
+		ReadTimeout:       requestTimeout,
+		WriteTimeout:      2 * requestTimeout,
+		MaxHeaderBytes:    1 << 16,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// 执行-serve：收到SIGINT/SIGTERM后优雅关闭
+func runServer(addr string, cfg serverConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	slog.Info("服务已启动", "addr", ln.Addr().String())
+	fmt.Fprintf(os.Stderr, "服务已启动：http://%s/calculate?T=70&rho=1.5&P=25（Ctrl+C停止）\n", ln.Addr())
+	if cfg.hub != nil {
+		fmt.Fprintf(os.Stderr, "持续推送：ws://%s/stream\n", ln.Addr())
+	}
+	err = serve(ctx, ln, cfg.handler())
+	if cfg.hub != nil {
+		cfg.hub.close() // 已接管的WebSocket连接不在Shutdown的等待范围内，单独关闭
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("服务已关闭")
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ctx取消后serve等待处理中的请求完成再返回nil
+func TestServeGracefulShutdown(t *testing.T) {
+	cfg := serverConfig{pressureType: pressureAbsolute, cache: newResultCache(16)}
+	started, release := make(chan struct{}), make(chan struct{})
+	h := cfg.handler()
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		h.ServeHTTP(w, r)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, ln, slow) }()
+
+	type response struct {
+		status int
+		res    Result
+		err    error
+	}
+	got := make(chan response, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/calculate?T=70&rho=1.5&P=25")
+		if err != nil {
+			got <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		var r response
+		r.status = resp.StatusCode
+		r.err = json.NewDecoder(resp.Body).Decode(&r.res)
+		got <- r
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("请求尚在处理时serve已返回：%v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	r := <-got
+	if r.err != nil {
+		t.Fatalf("处理中的请求未完成：%v", r.err)
+	}
+	if r.status != http.StatusOK || r.res.C == 0 {
+		t.Errorf("处理中的请求：状态%d，C=%v", r.status, r.res.C)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serve返回%v，应为nil", err)
+		}
+	case <-time.After(shutdownTimeout):
+		t.Fatal("serve未在shutdownTimeout内返回")
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+)
+
+// JSON模式下的错误对象
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+// JSON模式下单独输出的警告对象（-warn-stderr）
+type jsonWarning struct {
+	Warning string `json:"warning"`
+}
+
+// 以JSON输出结果；splitWarnings为真时out只写数据，警告逐条以JSON行写errOut
+func writeResultJSON(out, errOut io.Writer, res Result, splitWarnings, compact bool) error {
+	warnings := res.Warnings
+	if splitWarnings {
+		res.Warnings = nil
+	}
+	var v any = res
+	if compact {
+		v = compactResultOf(res)
+	}
+	if err := json.NewEncoder(out).Encode(v); err != nil {
+		return err
+	}
+	if splitWarnings {
+		enc := json.NewEncoder(errOut)
+		for _, w := range warnings {
+			if err := enc.Encode(jsonWarning{Warning: w}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compactResult -compact-json的精简结果，供带宽受限的嵌入式客户端使用；键名与完整结果的对应：
+//
+//	v  → schema_version（结构版本，与完整结果相同）
+//	c  → concentration_pct（%）
+//	tw → water_bp_c（℃）
+//	b  → bpr_c（℃）
+//	tl → solution_bp_c（℃）
+//	w  → warnings（无警告时省略）
+//
+// 不含单位表与输入回显，其余字段只在完整结果中提供
+type compactResult struct {
+	V  int      `json:"v"`
+	C  float64  `json:"c"`
+	Tw float64  `json:"tw"`
+	B  float64  `json:"b"`
+	Tl float64  `json:"tl"`
+	W  []string `json:"w,omitempty"`
+}
+
+func compactResultOf(res Result) compactResult {
+	return compactResult{V: res.SchemaVersion, C: res.C, Tw: res.Tw, B: res.BPR, Tl: res.Tl, W: res.Warnings}
+}
+
+// singleConfig 单点计算（默认模式）的选项，由main按命令行参数填写
+type singleConfig struct {
+	given   map[string]bool // 命令行已指定的参数
+	T, P, C float64         // -T、-P、-C
+	reading densityReading  // -rho（或由-current-ma换算）
+
+	pressureType string
+	atm          float64
+	basis        string
+	concUnit     string
+	midpoint     bool
+
+	report, datasheet, dot, treeJSON    bool
+	json, compactJSON, warnStderr       bool
+	tags, sensitivity, verbose          bool
+	snapConc, saltMass, metalGPL        bool
+	heatKW, massKg, expectTl, expectTol float64
+}
+
+// validate 检查单点计算的参数组合
+func (cfg singleConfig) validate() error {
+	if cfg.midpoint && (cfg.given["C"] || cfg.given["rho"]) {
+		return errors.New("-midpoint按浓度范围中点估算，不能与-C、-rho同用")
+	}
+	if cfg.given["heat-kw"] || cfg.given["mass-kg"] {
+		if cfg.heatKW <= 0 || cfg.massKg <= 0 {
+			return errors.New("估算升温时间需同时给出正的-heat-kw与-mass-kg")
+		}
+		if cfg.given["C"] {
+			return errors.New("估算升温时间需要实测温度，不能与-C同用")
+		}
+	}
+	// 已知浓度（含-midpoint）时没有密度，依赖密度的输出无从计算
+	if (cfg.given["C"] || cfg.midpoint) && (cfg.report || cfg.dot || cfg.treeJSON || cfg.tags || cfg.sensitivity || cfg.snapConc || cfg.concUnit == concUnitMolar || cfg.saltMass || cfg.metalGPL || opts.refine || opts.coupled) {
+		return errors.New("-C（已知浓度）与-midpoint不涉及密度，不能与report、-dot、-tree-json、-tags、-sensitivity、-snap-conc、-conc-unit molar、-salt-mass、-metal-gpl、-refine、-coupled同用")
+	}
+	return nil
+}
+
+// 辅助：单点计算出错时按输出模式报告并退出。JSON模式输出错误对象；
+// 文本模式写在结果位置，只有-expect-tl（回归校验）时以非零状态退出
+func (cfg singleConfig) fail(prefix string, err error) {
+	if cfg.json {
+		json.NewEncoder(os.Stdout).Encode(jsonError{Error: err.Error()})
+		exit(1)
+	}
+	if cfg.dot {
+		fmt.Fprintf(os.Stderr, "%s：%v\n", prefix, err)
+		exit(1)
+	}
+	fmt.Printf("%s：%v\n", prefix, err)
+	if cfg.given["expect-tl"] {
+		exit(1)
+	}
+	exit(0)
+}
+
+// runSingle 单点计算：读取（或交互输入）温度、密度与压力，计算并按所选格式输出
+func runSingle(cfg singleConfig) {
+	if cfg.json || cfg.dot || cfg.report || cfg.datasheet {
+		promptOut = os.Stderr
+	} else {
+		rg := SupportedRanges()
+		fmt.Printf("=== 高浓度硫酸钴极低负压（%g~%gkPa）BPR计算工具（温度自由输入版）===\n", rg.Pressure.Min, rg.Pressure.Max)
+		fmt.Printf("注：实测温度支持%g~%g℃任意值，密度支持高浓度对应范围（%.3f~%.3f g/cm³）\n",
+			rg.Temperature.Min, rg.Temperature.Max, rg.DensityAll.Min, rg.DensityAll.Max)
+		fmt.Println("---------------------------------------------------")
+	}
+
+	// 1. 读取用户输入（已知浓度时不需要温度和密度）
+	given := cfg.given
+	fromC := given["C"]
+	var T, rho float64
+	var err error
+	reading := cfg.reading
+	if !fromC {
+		if T, err = inputValue(given["T"], cfg.T, "请输入实测温度（℃）：", unitsTemperature, checkTemperature); err != nil {
+			cfg.fail("错误", err)
+		}
+	}
+	if !fromC && !cfg.midpoint {
+		// 读数换算到工艺温度后校验
+		densityAt := func(d densityReading) (float64, error) {
+			rho, err := d.at(T)
+			if err != nil {
+				return 0, err
+			}
+			return rho, checkDensity(T, rho)
+		}
+		if given["rho"] {
+			rho, err = densityAt(reading)
+		} else {
+			rho, err = promptValid("请输入实测密度（g/cm³，密度计在其他温度下测得时写作 密度@测量温度）：", func(line string) (float64, error) {
+				if reading, err = parseDensityReading(line); err != nil {
+					return 0, err
+				}
+				return densityAt(reading)
+			})
+		}
+		if err != nil {
+			cfg.fail("错误", err)
+		}
+		if reading.tagged && reading.measT != T {
+			slog.Info("密度已按同浓度换算到工艺温度", "rho_measured", reading.rho, "T_measured", reading.measT, "T", T, "rho", rho)
+		}
+	}
+
+	pressurePrompt := "请输入工艺压力（kPa）："
+	if cfg.pressureType == pressureGauge {
+		pressurePrompt = "请输入工艺压力（kPa，表压）："
+	}
+	var P float64
+	PInput, err := inputValue(given["P"], cfg.P, pressurePrompt, unitsPressure, func(v float64) (err error) {
+		if P, err = toAbsolutePressure(v, cfg.pressureType, cfg.atm); err != nil {
+			return err
+		}
+		return checkPressure(P)
+	})
+	if err != nil {
+		cfg.fail("错误", err)
+	}
+
+	// 2. 执行计算；由实测密度计算时保留明细，-dot、-tree-json、-tags直接复用，不再重算
+	var res Result
+	var s calcSteps
+	if fromC {
+		C := cfg.C
+		if cfg.basis == basisAnhydrous {
+			C = ToHydrate(C)
+		}
+		res, err = CalculateFromConcentration(C, P)
+		res.C = roundHalfUp(res.C, 1)
+	} else if cfg.midpoint {
+		res, err = calculateMidpoint(T, P)
+	} else if s, err = calculateSteps(T, rho, P); err == nil {
+		res, err = resultFromSteps(T, P, s)
+	}
+	if err != nil {
+		slog.Error("计算失败", "T", T, "rho", rho, "P", P, "err", err)
+		cfg.fail("计算失败", err)
+	}
+
+	if cfg.pressureType == pressureGauge {
+		res.PGauge = PInput
+	}
+	res.addWarning(pressurePrecisionWarning(PInput))
+	if cfg.concUnit == concUnitMolar {
+		res.Molarity = Molarity(res.C, res.Rho)
+	}
+	if cfg.saltMass {
+		res.SaltMassPerM3 = SaltMassPerM3(res.C, res.Rho)
+	}
+	if cfg.metalGPL {
+		res.CobaltGPL = roundHalfUp(CobaltGPL(res.C, res.Rho), 1)
+	}
+	if cfg.heatKW > 0 {
+		res.TimeToBoil = roundHalfUp(TimeToBoil(res.C, cfg.massKg, cfg.heatKW, res.T, res.Tl), 1)
+	}
+	if cfg.basis == basisAnhydrous {
+		res.CAnhydrous = roundHalfUp(ToAnhydrous(res.C), 1)
+	}
+	if cfg.report {
+		if err := fillReportProperties(&res); err != nil {
+			cfg.fail("计算失败", err)
+		}
+	}
+	if cfg.snapConc {
+		if res.SnappedC, err = NearestTableConcentration(T, res.C); err != nil {
+			cfg.fail("计算失败", err)
+		}
+	}
+
+	slog.Info("计算完成", "T", T, "rho", rho, "P", P, "C", res.C, "tw", res.Tw, "bpr", res.BPR, "tl", res.Tl)
+	for _, w := range res.Warnings {
+		slog.Warn(w)
+	}
+
+	pressure := formatPressure(P, cfg.pressureType, cfg.atm)
+	switch {
+	case cfg.dot:
+		if err := writeDOT(os.Stdout, T, rho, P, s); err != nil {
+			slog.Error("DOT输出失败", "err", err)
+			exit(1)
+		}
+		return
+	case cfg.treeJSON:
+		if err := writeStepTree(os.Stdout, T, rho, P, s); err != nil {
+			cfg.fail("计算失败", err)
+		}
+		return
+	case cfg.json:
+		if err := writeResultJSON(os.Stdout, os.Stderr, res, cfg.warnStderr, cfg.compactJSON); err != nil {
+			slog.Error("JSON输出失败", "err", err)
+			exit(1)
+		}
+		return
+	case cfg.datasheet:
+		if err := writeDatasheet(os.Stdout, res, pressure); err != nil {
+			slog.Error("数据表输出失败", "err", err)
+			exit(1)
+		}
+		return
+	case cfg.report:
+		if err := writeReport(os.Stdout, res, pressure); err != nil {
+			slog.Error("报告输出失败", "err", err)
+			exit(1)
+		}
+		return
+	}
+
+	// 3. 输出结果（匹配你的格式）
+	fmt.Println("---------------------------------------------------")
+	if fromC {
+		if cfg.basis == basisAnhydrous {
+			fmt.Printf("已知浓度：%.1f%%（无水CoSO4计，折合七水合物%.1f%%），工艺压力：%s\n", cfg.C, res.C, pressure)
+		} else {
+			fmt.Printf("已知浓度：%.1f%%，工艺压力：%s\n", res.C, pressure)
+		}
+	} else if cfg.midpoint {
+		fmt.Printf("【估算】实测温度：%.1f℃，工艺压力：%s，未测密度\n", T, pressure)
+		fmt.Printf("按可计算浓度范围中点取浓度：%.1f%%\n", res.C)
+	} else {
+		fmt.Printf("实测温度：%.1f℃，实测密度：%.3f g/cm³，工艺压力：%s\n", T, rho, pressure)
+		if reading.tagged && reading.measT != T {
+			fmt.Printf("（密度计读数%.3f g/cm³于%.1f℃测得，已按同浓度换算到%.1f℃）\n", reading.rho, reading.measT, T)
+		}
+		switch {
+		case cfg.concUnit == concUnitMolar:
+			fmt.Printf("反查浓度（温度+密度双插值）：%.3f mol/L（质量分数%.1f%%）\n", res.Molarity, res.C)
+		case cfg.basis == basisAnhydrous:
+			fmt.Printf("反查浓度（温度+密度双插值）：%.1f%%（无水CoSO4计；七水合物计%.1f%%）\n", res.CAnhydrous, res.C)
+		default:
+			fmt.Printf("反查浓度（温度+密度双插值）：%.1f%%\n", res.C)
+		}
+		if cfg.verbose {
+			fmt.Printf("密度自洽残差（实测−按浓度回算）：%+.5f g/cm³\n", res.RhoResidual)
+		}
+		if res.CTol > 0 {
+			fmt.Printf("浓度不确定度（密度计±%g g/cm³）：±%.2f%%\n", opts.densityTol, res.CTol)
+		}
+		if cfg.snapConc {
+			fmt.Printf("最近的表列浓度（填报用，非计算值）：%g%%\n", res.SnappedC)
+		}
+	}
+	if cfg.saltMass {
+		fmt.Printf("每m³溶液含七水合硫酸钴：%.0f kg\n", res.SaltMassPerM3)
+	}
+	if cfg.metalGPL {
+		fmt.Printf("钴金属含量：%.1f g/L\n", res.CobaltGPL)
+	}
+	if cfg.heatKW > 0 {
+		if res.TimeToBoil > 0 {
+			fmt.Printf("升温至沸点（%g kg，%g kW，比热%.2f kJ/(kg·K)）：约%.1f分钟（粗估，不计热损失）\n",
+				cfg.massKg, cfg.heatKW, SpecificHeat(res.C), res.TimeToBoil)
+		} else {
+			fmt.Println("实测温度已不低于溶液沸点，无需升温")
+		}
+	}
+	fmt.Printf("纯水沸点（你的蒸气压表）：%.1f℃\n", res.Tw)
+	if cfg.verbose {
+		fmt.Printf("二次蒸汽冷凝温度（汽相侧）：%.1f℃\n", res.TCond)
+	}
+	if opts.band {
+		fmt.Printf("极低负压BPR：%.1f ± %.1f℃（约95%%区间）\n", res.BPR, res.BPRBand)
+		fmt.Printf("溶液实际沸点（液相侧，工艺温度）：%.1f ± %.1f℃\n", res.Tl, res.BPRBand)
+	} else {
+		fmt.Printf("极低负压BPR：%.1f℃\n", res.BPR)
+		fmt.Printf("溶液实际沸点（液相侧，工艺温度）：%.1f℃\n", res.Tl)
+	}
+	if res.Confidence != nil {
+		fmt.Printf("可信度评分：%d/100\n", *res.Confidence)
+	}
+	if cfg.verbose {
+		fmt.Printf("对照：同浓度溶液常压沸点：%.1f℃\n", res.TlAtm)
+		fmt.Printf("渗透系数（由BPR反推，ΔTb = Kb·m·i·φ）：%.3f\n", res.OsmoticCoefficient)
+		fmt.Printf("沸点分解：%.1f℃ = 100℃ %+.1f℃（压力） %+.1f℃（浓度BPR）\n", res.Tl, res.PressureShift, res.BPR)
+	}
+	if bprCalibration.points > 0 {
+		fmt.Printf("BPR已按%d个现场数据点校正：模型值×%.3f%+.2f℃\n", bprCalibration.points, bprCalibration.scale, bprCalibration.offset)
+	}
+	if opts.coupled {
+		fmt.Printf("温度-密度耦合迭代：%d次\n", res.CoupledIterations)
+	}
+	if opts.refine {
+		fmt.Printf("浓度迭代修正：%d次，密度残差%.5f g/cm³\n", res.RefineIterations, res.RefineResidual)
+	}
+	for _, w := range res.Warnings {
+		fmt.Printf("警告：%s\n", w)
+	}
+	fmt.Println("---------------------------------------------------")
+
+	if cfg.sensitivity {
+		dT, dRho, dP, err := Sensitivities(T, rho, P)
+		if err != nil {
+			fmt.Printf("偏导计算失败：%v\n", err)
+		} else {
+			fmt.Printf("溶液沸点偏导：∂tl/∂T=%+.4f ℃/℃，∂tl/∂rho=%+.2f ℃/(g/cm³)，∂tl/∂P=%+.4f ℃/kPa\n", dT, dRho, dP)
+			fmt.Println("---------------------------------------------------")
+		}
+	}
+
+	if cfg.tags {
+		tags := stepTags(s)
+		for _, name := range tagNames {
+			fmt.Printf("%s=%g\n", name, tags[name])
+		}
+		fmt.Println("---------------------------------------------------")
+	}
+
+	// 4. 与期望沸点比对（用于对照历史参考点做回归校验）
+	if given["expect-tl"] {
+		diff := roundHalfUp(res.Tl-cfg.expectTl, 1) // tl已保留1位小数，差值同精度、同舍入规则比较
+		fmt.Printf("与期望沸点差值：%+.1f℃（期望%.1f℃，容差±%.1f℃）\n", diff, cfg.expectTl, cfg.expectTol)
+		if math.Abs(diff) > cfg.expectTol {
+			fmt.Println("比对失败：差值超出容差")
+			exit(1)
+		}
+		fmt.Println("比对通过")
+	}
+
+	// 仅交互运行时暂停（防止双击启动的窗口立即关闭）；管道或重定向输入时直接退出，便于脚本与CI调用
+	if stdinIsTerminal() {
+		fmt.Println("按回车键继续...")
+		fmt.Scanln()
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 持续推送（-continuous）：WebSocket端点/stream。任一客户端发送一组工况{"T":..,"rho":..,"P":..}，
+// 服务端按/calculate的同一计算核心重算，结果推送给所有订阅者；输入有误时仅回复发送方{"error": ...}。
+// 只实现RFC 6455中本场景所需的部分：文本帧、不分片、ping/pong与close
+const (
+	wsGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxPayload    = 4096             // 单条工况消息的长度上限（字节）
+	wsWriteTimeout  = 5 * time.Second  // 单次推送的写超时，超时视为客户端失联
+	wsReadIdleLimit = 10 * time.Minute // 无任何消息（含ping）的最长间隔
+
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// streamHub /stream的订阅者集合与最近一次结果（新订阅者连上即收到）
+type streamHub struct {
+	mu     sync.Mutex
+	subs   map[*wsConn]struct{}
+	last   []byte
+	closed bool
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{subs: map[*wsConn]struct{}{}}
+}
+
+// 辅助：登记订阅者并补发最近一次结果；服务已关闭时返回false
+func (h *streamHub) add(c *wsConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.subs[c] = struct{}{}
+	if h.last != nil {
+		c.send(h.last)
+	}
+	return true
+}
+
+func (h *streamHub) remove(c *wsConn) {
+	h.mu.Lock()
+	delete(h.subs, c)
+	h.mu.Unlock()
+}
+
+// broadcast 推送结果给所有订阅者；每个订阅者只保留最新一条未发出的结果，慢客户端不阻塞其他订阅者
+func (h *streamHub) broadcast(msg []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = msg
+	for c := range h.subs {
+		c.send(msg)
+	}
+}
+
+// close 服务关闭时断开所有订阅者
+func (h *streamHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.subs {
+		c.shutdown()
+	}
+	h.subs = nil
+}
+
+// wsConn 一个已完成握手的WebSocket连接：读由handleStream的循环负责；推送的结果经writeLoop写出，
+// pong与错误回复由读循环直接写（均经writeFrame串行化）
+type wsConn struct {
+	conn    net.Conn
+	rd      *bufio.Reader
+	pending chan []byte // 容量1：只保留最新一条待推送的结果
+	done    chan struct{}
+	once    sync.Once
+	wmu     sync.Mutex
+	werr    error // 首次写失败的错误（受wmu保护）；此后不再向连接写任何内容
+}
+
+// send 投递待推送的消息，已有未发出的消息时以新消息替换
+func (c *wsConn) send(msg []byte) {
+	for {
+		select {
+		case c.pending <- msg:
+			return
+		default:
+		}
+		select {
+		case <-c.pending:
+		default:
+		}
+	}
+}
+
+// reply 直接向该连接写一条回复（错误回复）：不经pending，不会被随后的广播替换；在读循环中调用
+func (c *wsConn) reply(msg []byte) error {
+	return c.writeFrame(wsOpText, msg)
+}
+
+// shutdown 结束连接（可重复调用）：发送close帧后关闭底层连接
+func (c *wsConn) shutdown() {
+	c.once.Do(func() {
+		close(c.done)
+		c.writeFrame(wsOpClose, []byte{0x03, 0xE9}) // 1001：服务端离开
+		c.conn.Close()
+	})
+}
+
+func (c *wsConn) writeLoop() {
+	for {
+		select {
+		case msg := <-c.pending:
+			if err := c.writeFrame(wsOpText, msg); err != nil {
+				c.shutdown()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// writeFrame 写一个不分片、不加掩码的帧（服务端发出的帧不加掩码）；
+// 写失败后连接状态未知（可能只写出半帧），之后的调用直接返回首次的错误
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.werr != nil {
+		return c.werr
+	}
+	hdr := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := c.conn.Write(append(hdr, payload...)); err != nil {
+		c.werr = err
+		return err
+	}
+	return nil
+}
+
+// readFrame 读一个客户端帧并去掩码；客户端帧必须加掩码，不支持分片
+func (c *wsConn) readFrame() (op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.rd, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	if hdr[0]&0x80 == 0 || hdr[0]&0x0F == 0 {
+		return 0, nil, errors.New("不支持分片消息")
+	}
+	if hdr[1]&0x80 == 0 {
+		return 0, nil, errors.New("客户端帧未加掩码")
+	}
+	op = hdr[0] & 0x0F
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.rd, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.rd, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > wsMaxPayload {
+		return 0, nil, fmt.Errorf("消息长度%d字节超过上限%d", n, wsMaxPayload)
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rd, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.rd, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+// 辅助：检查WebSocket握手请求，返回客户端的Sec-WebSocket-Key
+func webSocketKey(r *http.Request) (string, error) {
+	if r.Method != http.MethodGet ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		return "", errors.New("需以WebSocket协议连接")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return "", errors.New("仅支持WebSocket版本13")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return "", errors.New("缺少Sec-WebSocket-Key")
+	}
+	return key, nil
+}
+
+// webSocketAccept 握手响应的Sec-WebSocket-Accept（RFC 6455 4.2.2）
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// 辅助：WebSocket握手，成功后接管底层连接。握手请求有误时回复400；
+// 接管之后w不再可用，失败只返回错误，由调用方放弃该连接
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	key, err := webSocketKey(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, jsonError{Error: err.Error()})
+		return nil, err
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		err := errors.New("连接不支持协议升级")
+		writeJSON(w, http.StatusInternalServerError, jsonError{Error: err.Error()})
+		return nil, err
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{}) // 清除服务器为普通请求设置的读写超时
+	resp := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, rd: rw.Reader, pending: make(chan []byte, 1), done: make(chan struct{})}, nil
+}
+
+// 客户端发送的工况；三项均为必填
+type streamInput struct {
+	T   *float64 `json:"T"`
+	Rho *float64 `json:"rho"`
+	P   *float64 `json:"P"`
+}
+
+// GET /stream（WebSocket）：订阅推送，并可发送新的工况
+func (cfg serverConfig) handleStream(w http.ResponseWriter, r *http.Request) {
+	c, err := upgradeWebSocket(w, r)
+	if err != nil {
+		slog.Debug("推送握手失败", "remote", r.RemoteAddr, "err", err)
+		return
+	}
+	if !cfg.hub.add(c) {
+		c.shutdown()
+		return
+	}
+	slog.Debug("推送订阅者已连接", "remote", r.RemoteAddr)
+	defer func() {
+		cfg.hub.remove(c)
+		c.shutdown()
+		slog.Debug("推送订阅者已断开", "remote", r.RemoteAddr)
+	}()
+	go c.writeLoop()
+
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(wsReadIdleLimit))
+		op, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch op {
+		case wsOpText:
+			if err := cfg.streamCalculate(c, payload); err != nil {
+				return
+			}
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return
+			}
+		case wsOpPong:
+		case wsOpClose:
+			return
+		default:
+			if err := c.reply(encodeJSON(jsonError{Error: "仅接受文本消息"})); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// 辅助：按一条工况消息重算并广播；输入或计算错误只回复发送方，返回的是回复写失败的错误
+func (cfg serverConfig) streamCalculate(c *wsConn, payload []byte) error {
+	var in streamInput
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return c.reply(encodeJSON(jsonError{Error: fmt.Sprintf("工况消息不是有效的JSON：%v", err)}))
+	}
+	if in.T == nil || in.Rho == nil || in.P == nil {
+		return c.reply(encodeJSON(jsonError{Error: `工况消息需包含T、rho、P，如{"T":70,"rho":1.5,"P":25}`}))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := cfg.calculate(ctx, *in.T, *in.Rho, *in.P)
+	if err != nil {
+		return c.reply(encodeJSON(jsonError{Error: err.Error()}))
+	}
+	cfg.hub.broadcast(encodeJSON(res))
+	return nil
+}
+
+// 辅助：编码推送的JSON；编码失败时推送错误对象
+func encodeJSON(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(jsonError{Error: fmt.Sprintf("结果编码失败：%v", err)})
+	}
+	return b
+}
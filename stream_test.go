@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 辅助：客户端写一个加掩码的帧
+func writeClientFrame(w io.Writer, op byte, payload []byte) error {
+	hdr := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, 0x80|byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 0x80|126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 0x80|127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	hdr = append(hdr, mask[:]...)
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+	_, err := w.Write(append(hdr, masked...))
+	return err
+}
+
+// 辅助：客户端读一个服务端帧（不加掩码）
+func readServerFrame(r io.Reader) (op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	if hdr[1]&0x80 != 0 {
+		return 0, nil, errors.New("服务端帧不应加掩码")
+	}
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	payload = make([]byte, n)
+	_, err = io.ReadFull(r, payload)
+	return hdr[0] & 0x0F, payload, err
+}
+
+// RFC 6455 1.3中的示例
+func TestWebSocketAccept(t *testing.T) {
+	if got := webSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q", got)
+	}
+}
+
+// 不同长度编码（7位、16位、64位）的帧经writeFrame/readFrame往返不变
+func TestWebSocketFrameRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 5, 125, 126, 300, 70000} {
+		payload := bytes.Repeat([]byte("x"), n)
+
+		server, client := net.Pipe()
+		c := &wsConn{conn: server, rd: bufio.NewReader(server)}
+		go c.writeFrame(wsOpText, payload)
+		op, got, err := readServerFrame(client)
+		if err != nil || op != wsOpText || !bytes.Equal(got, payload) {
+			t.Errorf("%d字节服务端帧：op=%x，长度%d，%v", n, op, len(got), err)
+		}
+
+		if n <= wsMaxPayload {
+			go writeClientFrame(client, wsOpText, payload)
+			op, got, err = c.readFrame()
+			if err != nil || op != wsOpText || !bytes.Equal(got, payload) {
+				t.Errorf("%d字节客户端帧：op=%x，长度%d，%v", n, op, len(got), err)
+			}
+		}
+		server.Close()
+		client.Close()
+	}
+
+	// 超过上限的客户端消息被拒绝
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	c := &wsConn{conn: server, rd: bufio.NewReader(server)}
+	go writeClientFrame(client, wsOpText, make([]byte, wsMaxPayload+1))
+	if _, _, err := c.readFrame(); err == nil || !strings.Contains(err.Error(), "超过上限") {
+		t.Errorf("超长消息：%v，应报超过上限", err)
+	}
+}
+
+// failingConn 第一次写即失败并记录写次数
+type failingConn struct {
+	net.Conn
+	writes int
+}
+
+func (c *failingConn) Write(b []byte) (int, error) {
+	c.writes++
+	return 0, errors.New("连接已断开")
+}
+
+func (c *failingConn) SetWriteDeadline(time.Time) error { return nil }
+func (c *failingConn) Close() error                     { return nil }
+
+// 写失败后不再向连接写任何内容（含关闭时的close帧）
+func TestWebSocketStopsWritingAfterError(t *testing.T) {
+	fc := &failingConn{}
+	c := &wsConn{conn: fc, pending: make(chan []byte, 1), done: make(chan struct{})}
+	first := c.writeFrame(wsOpText, []byte("a"))
+	if first == nil {
+		t.Fatal("写失败应返回错误")
+	}
+	if err := c.writeFrame(wsOpPong, nil); err != first {
+		t.Errorf("第二次写返回%v，应为首次的错误", err)
+	}
+	c.shutdown()
+	if fc.writes != 1 {
+		t.Errorf("写失败后又写了%d次", fc.writes-1)
+	}
+}
+
+// 辅助：对/stream完成握手，返回连接与其读取器
+func dialStream(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	const key = "dGhlIHNhbXBsZSBub25jZQ=="
+	req := "GET /stream HTTP/1.1\r\nHost: " + addr + "\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	rd := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(rd, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		t.Fatalf("握手响应：%s，Sec-WebSocket-Accept=%q", resp.Status, resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn, rd
+}
+
+// 经httptest完成握手：工况消息的结果推送给所有订阅者，错误只回复发送方，ping得到同样内容的pong
+func TestStreamHandshakeAndPush(t *testing.T) {
+	cfg := serverConfig{pressureType: pressureAbsolute, cache: newResultCache(16), hub: newStreamHub()}
+	srv := httptest.NewServer(cfg.handler())
+	defer srv.Close()
+	defer cfg.hub.close()
+	addr := srv.Listener.Addr().String()
+
+	// 非WebSocket请求得到400与错误说明
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jerr jsonError
+	json.NewDecoder(resp.Body).Decode(&jerr)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || jerr.Error == "" {
+		t.Errorf("普通GET /stream：%s，%q", resp.Status, jerr.Error)
+	}
+
+	sender, senderRd := dialStream(t, addr)
+	_, watcherRd := dialStream(t, addr)
+
+	if err := writeClientFrame(sender, wsOpPing, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if op, payload, err := readServerFrame(senderRd); err != nil || op != wsOpPong || string(payload) != "hi" {
+		t.Errorf("ping：op=%x payload=%q %v，应为同样内容的pong", op, payload, err)
+	}
+
+	if err := writeClientFrame(sender, wsOpText, []byte(`{"T":70}`)); err != nil {
+		t.Fatal(err)
+	}
+	_, payload, err := readServerFrame(senderRd)
+	if err != nil || json.Unmarshal(payload, &jerr) != nil || !strings.Contains(jerr.Error, "需包含T、rho、P") {
+		t.Errorf("缺项的工况：%s %v", payload, err)
+	}
+
+	if err := writeClientFrame(sender, wsOpText, []byte(`{"T":70,"rho":1.5,"P":25}`)); err != nil {
+		t.Fatal(err)
+	}
+	want, err := cfg.calculate(t.Context(), 70, 1.5, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, rd := range map[string]*bufio.Reader{"发送方": senderRd, "订阅者": watcherRd} {
+		op, payload, err := readServerFrame(rd)
+		var res Result
+		if err != nil || op != wsOpText || json.Unmarshal(payload, &res) != nil {
+			t.Errorf("%s：op=%x %s %v", name, op, payload, err)
+			continue
+		}
+		if res.C != want.C || res.Tl != want.Tl {
+			t.Errorf("%s：C=%v tl=%v，应为C=%v tl=%v", name, res.C, res.Tl, want.C, want.Tl)
+		}
+	}
+}
+
+// 错误回复直接写到连接上：写循环正卡在慢客户端上时，随后的广播不会把尚未发出的错误回复替换掉
+func TestStreamReplyNotReplacedByBroadcast(t *testing.T) {
+	server, client := net.Pipe()
+	c := &wsConn{conn: server, rd: bufio.NewReader(server), pending: make(chan []byte, 1), done: make(chan struct{})}
+	defer c.shutdown()
+	defer client.Close() // 先关闭客户端，shutdown的close帧不必等写超时
+	go c.writeLoop()
+
+	c.send([]byte(`{"n":1}`)) // 客户端尚未读取，写循环阻塞在这一条上
+	for len(c.pending) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	replied := make(chan error, 1)
+	go func() { replied <- c.reply(encodeJSON(jsonError{Error: "输入有误"})) }()
+	time.Sleep(20 * time.Millisecond) // 让回复先发出（此时等在写锁上），再来一条广播
+	c.send([]byte(`{"n":2}`))
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var got []string
+	for range 3 {
+		_, payload, err := readServerFrame(client)
+		if err != nil {
+			t.Fatalf("已收到%q，读下一条：%v", got, err)
+		}
+		got = append(got, string(payload))
+	}
+	if err := <-replied; err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != `{"n":1}` || !slices.Contains(got, `{"error":"输入有误"}`) || !slices.Contains(got, `{"n":2}`) {
+		t.Errorf("收到%q，应依次为第1条结果，再是错误回复与第2条结果", got)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// 辅助：按start:end:step生成扫描点（按序号累加，避免浮点步长累积误差）
+func sweepRange(start, end, step float64) ([]float64, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("扫描步长须为正，当前%g", step)
+	}
+	if end < start {
+		return nil, fmt.Errorf("扫描终点%g小于起点%g", end, start)
+	}
+	var pts []float64
+	for i := 0; ; i++ {
+		v := start + float64(i)*step
+		if v > end+step*1e-9 {
+			break
+		}
+		pts = append(pts, math.Min(v, end))
+	}
+	return pts, nil
+}
+
+// concentrationSweepPoints 温度T下的浓度扫描点：等步长点并入相邻温度行在[start, end]内的全部浓度断点
+// 断点（如55℃行的51.8）是实测数据的真实顶点，并入后曲线不会在步长之间被插值抹平
+// 超出相邻两行共有浓度区间的点（密度会被截断为端点值）不输出，dropped返回略去的点数
+func concentrationSweepPoints(T, start, end, step float64) (pts []float64, dropped int, err error) {
+	raw, err := sweepRange(start, end, step)
+	if err != nil {
+		return nil, 0, err
+	}
+	tLeft, tRight, err := findAdjacentTemps(T)
+	if err != nil {
+		return nil, 0, err
+	}
+	pairsLeft, pairsRight := densityRows()[tLeft], densityRows()[tRight]
+	for _, c := range mergedConcentrations(pairsLeft, pairsRight) {
+		if c >= start && c <= end {
+			raw = append(raw, c)
+		}
+	}
+	sort.Float64s(raw)
+
+	lo := math.Max(pairsLeft[0][0], pairsRight[0][0])
+	hi := math.Min(pairsLeft[len(pairsLeft)-1][0], pairsRight[len(pairsRight)-1][0])
+	for i, c := range raw {
+		if i > 0 && math.Abs(c-raw[i-1]) < 1e-9 {
+			continue
+		}
+		if c < lo || c > hi {
+			dropped++
+			continue
+		}
+		pts = append(pts, c)
+	}
+	return pts, dropped, nil
+}
+
+// runConcentrationSweep 浓度扫描：输出温度T下各浓度的密度CSV；hasP时附带该压力下的纯水沸点、BPR和溶液沸点
+func runConcentrationSweep(out io.Writer, T, P float64, hasP bool, spec string) error {
+	vals, err := parseColonFloats(spec, 3)
+	if err != nil {
+		return err
+	}
+	concs, dropped, err := concentrationSweepPoints(T, vals[0], vals[1], vals[2])
+	if err != nil {
+		return err
+	}
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "注意：%d个扫描点超出%.1f℃下密度表的浓度范围，已略去\n", dropped, T)
+	}
+
+	var tw float64
+	if hasP {
+		if tw, err = getPureWaterBoilingPoint(P); err != nil {
+			return err
+		}
+	}
+
+	w := newRecordWriter(out)
+	header := []string{"C", "rho"}
+	if hasP {
+		header = append(header, "tw", "bpr", "tl", "error")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, C := range concs {
+		rho, err := DensityFor(T, C)
+		if err != nil {
+			return err
+		}
+		rec := []string{strconv.FormatFloat(C, 'f', -1, 64), strconv.FormatFloat(rho, 'f', 4, 64)}
+		if hasP {
+			_, _, bpr, tl, err := boilingPointForConcentration(C, tw)
+			if err != nil {
+				rec = append(rec, strconv.FormatFloat(tw, 'f', 1, 64), "", "", err.Error())
+			} else {
+				rec = append(rec, strconv.FormatFloat(tw, 'f', 1, 64), strconv.FormatFloat(bpr, 'f', 1, 64), strconv.FormatFloat(tl, 'f', 1, 64), "")
+			}
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// runPressureSweep 压力扫描：同一样品（T、rho）在各压力下的纯水沸点、BPR和溶液沸点CSV
+// 扫描范围按-pressure-type解释，P列原样输出该值；超出压力范围的点错误写入error列
+func runPressureSweep(out io.Writer, T, rho float64, spec, pressureType string, atm float64) error {
+	vals, err := parseColonFloats(spec, 3)
+	if err != nil {
+		return err
+	}
+	pressures, err := sweepRange(vals[0], vals[1], vals[2])
+	if err != nil {
+		return err
+	}
+
+	w := newRecordWriter(out)
+	if err := w.Write([]string{"P", "tw", "bpr", "tl", "error"}); err != nil {
+		return err
+	}
+	for _, p := range pressures {
+		rec := []string{strconv.FormatFloat(p, 'f', -1, 64)}
+		res, err := func() (Result, error) {
+			P, err := toAbsolutePressure(p, pressureType, atm)
+			if err != nil {
+				return Result{}, err
+			}
+			return Calculate(T, rho, P)
+		}()
+		if err != nil {
+			rec = append(rec, "", "", "", err.Error())
+		} else {
+			rec = append(rec, strconv.FormatFloat(res.Tw, 'f', 1, 64), strconv.FormatFloat(res.BPR, 'f', 1, 64), strconv.FormatFloat(res.Tl, 'f', 1, 64), "")
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// 执行-sweep-C：温度必填，压力可选（按-pressure-type换算）
+func runConcentrationSweepFlags(spec string, given map[string]bool, T, P float64, pressureType string, atm float64) error {
+	if !given["T"] {
+		return fmt.Errorf("浓度扫描需用-T指定温度")
+	}
+	if given["P"] {
+		var err error
+		if P, err = toAbsolutePressure(P, pressureType, atm); err != nil {
+			return err
+		}
+	}
+	return runConcentrationSweep(os.Stdout, T, P, given["P"], spec)
+}
+
+// 执行-sweep-P：温度和密度必填
+func runPressureSweepFlags(spec string, given map[string]bool, T float64, reading densityReading, pressureType string, atm float64) error {
+	if !given["T"] || !given["rho"] {
+		return fmt.Errorf("压力扫描需用-T和-rho指定样品")
+	}
+	rho, err := reading.at(T)
+	if err != nil {
+		return err
+	}
+	return runPressureSweep(os.Stdout, T, rho, spec, pressureType, atm)
+}
+
+// 执行-target-tl：输出达到目标沸点所需的料液密度
+func runTargetDensity(targetTl float64, given map[string]bool, T, P float64, pressureType string, atm float64) error {
+	if !given["T"] || !given["P"] {
+		return fmt.Errorf("求目标密度需用-T和-P指定温度与工艺压力")
+	}
+	PAbs, err := toAbsolutePressure(P, pressureType, atm)
+	if err != nil {
+		return err
+	}
+	rho, err := DensityForBoilingPoint(T, PAbs, targetTl)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%.1f℃、%s下溶液沸点为%.1f℃所需的料液密度：%.3f g/cm³\n", T, formatPressure(PAbs, pressureType, atm), targetTl, rho)
+	return nil
+}
+
+// 执行-max-tl：由样品（T、rho）反查浓度，输出溶液沸点不超过上限的最高压力（按-pressure-type表示）
+func runMaxPressure(maxTl float64, given map[string]bool, T float64, reading densityReading, pressureType string, atm float64) error {
+	if !given["T"] || !given["rho"] {
+		return fmt.Errorf("求压力上限需用-T和-rho指定样品")
+	}
+	rho, err := reading.at(T)
+	if err != nil {
+		return err
+	}
+	C, err := getConcentration(T, rho)
+	if err != nil {
+		return err
+	}
+	P, err := MaxPressureForBoilingLimit(C, maxTl)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("浓度%.1f%%，溶液沸点不超过%.1f℃的最高压力：%.2f kPa（绝压）\n", C, maxTl, P)
+	if pressureType == pressureGauge {
+		fmt.Printf("折合表压：%.2f kPa（当地大气压%.1f kPa）\n", P-atm, atm)
+	}
+	return nil
+}
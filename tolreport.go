@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+)
+
+// concMethod 一种浓度反查方法（-tolerance-report比较用）
+type concMethod struct {
+	name  string
+	solve func(T, rho float64) (float64, error)
+}
+
+// 辅助：在临时设置的计算选项下反查浓度（取整前的值），结束后恢复原选项
+func solveWithOptions(set func(*calcOptions)) func(T, rho float64) (float64, error) {
+	return func(T, rho float64) (float64, error) {
+		saved := opts
+		defer func() { opts = saved }()
+		set(&opts)
+		s, err := getConcentrationSteps(context.Background(), T, rho)
+		return s.exactC, err
+	}
+}
+
+// 已实现的浓度反查方法
+func concentrationMethods() []concMethod {
+	return []concMethod{
+		{"linear", solveWithOptions(func(o *calcOptions) { o.concInterp, o.coupled, o.refine = concInterpLinear, false, false })},
+		{"pchip", solveWithOptions(func(o *calcOptions) { o.concInterp, o.coupled, o.refine = concInterpPCHIP, false, false })},
+		{"bilinear", func(T, rho float64) (float64, error) {
+			saved := opts.concInterp
+			defer func() { opts.concInterp = saved }()
+			opts.concInterp = concInterpLinear
+			return directConcentration(T, rho)
+		}},
+		{"coupled", solveWithOptions(func(o *calcOptions) { o.concInterp, o.coupled, o.refine = concInterpLinear, true, false })},
+	}
+}
+
+// methodDiff 两种方法在网格上的差异统计（浓度%）
+type methodDiff struct {
+	a, b       string
+	max, sumSq float64
+	n          int
+	at         struct{ T, rho, ca, cb float64 } // 差异最大处
+}
+
+// runToleranceReport 在温度×浓度网格（同往返校验）上由线性模型生成(T, rho)样本，
+// 用各反查方法分别求浓度，两两比较并输出最大差、RMS差及最大差所在位置
+func runToleranceReport(w io.Writer) error {
+	temps, concs := defaultInversionGrid()
+	methods := concentrationMethods()
+
+	var diffs []*methodDiff
+	for i := range methods {
+		for j := i + 1; j < len(methods); j++ {
+			diffs = append(diffs, &methodDiff{a: methods[i].name, b: methods[j].name})
+		}
+	}
+
+	saved := opts.concInterp
+	opts.concInterp = concInterpLinear
+	defer func() { opts.concInterp = saved }()
+
+	samples := 0
+	for _, T := range temps {
+		for _, C := range concs {
+			rho, err := DensityFor(T, C)
+			if err != nil {
+				return err
+			}
+			rho = roundHalfUp(rho, 3) // 与密度计读数同精度
+			got := make([]float64, len(methods))
+			for i, m := range methods {
+				if got[i], err = m.solve(T, rho); err != nil {
+					return fmt.Errorf("%s在T=%.1f℃、rho=%.3f处反查失败：%w", m.name, T, rho, err)
+				}
+			}
+			samples++
+			k := 0
+			for i := range methods {
+				for j := i + 1; j < len(methods); j++ {
+					d := diffs[k]
+					delta := got[i] - got[j]
+					d.sumSq += delta * delta
+					d.n++
+					if math.Abs(delta) > d.max {
+						d.max = math.Abs(delta)
+						d.at.T, d.at.rho, d.at.ca, d.at.cb = T, rho, got[i], got[j]
+					}
+					k++
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "浓度反查方法两两比较：%d个温度 × %d个浓度，共%d个(T, rho)样本\n", len(temps), len(concs), samples)
+	fmt.Fprintf(w, "%-20s %10s %10s  %s\n", "方法对", "最大差%", "RMS差%", "最大差位置")
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%-20s %10.3f %10.3f  T=%.1f℃ rho=%.3f（%.3f%% vs %.3f%%）\n",
+			d.a+" vs "+d.b, d.max, math.Sqrt(d.sumSq/float64(d.n)), d.at.T, d.at.rho, d.at.ca, d.at.cb)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// stepTree 计算明细的树形JSON（-tree-json）：按计算步骤分层，便于调试界面逐级展开
+type stepTree struct {
+	Input struct {
+		T   float64 `json:"temperature_c"`
+		Rho float64 `json:"density_g_cm3"`
+		P   float64 `json:"pressure_kpa"`
+	} `json:"input"`
+	Concentration treeConcentration `json:"concentration"`
+	Vapor         treeVapor         `json:"vapor"`
+	BPR           treeBPR           `json:"bpr"`
+	Tl            float64           `json:"solution_bp_c"`
+	Warnings      []string          `json:"warnings,omitempty"`
+}
+
+// treeConcentration 浓度反查：相邻温度行及各行上的等效密度、反查浓度
+type treeConcentration struct {
+	Temps          [2]float64  `json:"bracket_temps_c"`
+	Densities      [2]float64  `json:"bracket_densities_g_cm3"`
+	Concentrations [2]float64  `json:"bracket_concentrations_pct"`
+	C              float64     `json:"concentration_pct"`
+	Clamps         []ClampNote `json:"clamps,omitempty"`
+}
+
+// treeVapor 纯水沸点：蒸气压表中包围P的两点
+type treeVapor struct {
+	Pressures [2]float64 `json:"bracket_pressures_kpa"`
+	Temps     [2]float64 `json:"bracket_temps_c"`
+	Tw        float64    `json:"water_bp_c"`
+}
+
+// treeBPR BPR：常压值、压力修正与结果
+type treeBPR struct {
+	Atm float64 `json:"atm_c"`
+	K   float64 `json:"k"`
+	BPR float64 `json:"bpr_c"`
+}
+
+// vaporBracket 蒸气压表中包围P的相邻两点（P恰为表中压力时取以其为上端的区间，首点除外）
+func vaporBracket(P float64) (pressures, temps [2]float64, err error) {
+	for i := 0; i < len(VaporPressureTable)-1; i++ {
+		lo, hi := VaporPressureTable[i], VaporPressureTable[i+1]
+		if P >= lo.Pressure_kPa && P <= hi.Pressure_kPa {
+			return [2]float64{lo.Pressure_kPa, hi.Pressure_kPa}, [2]float64{lo.Temp_C, hi.Temp_C}, nil
+		}
+	}
+	return pressures, temps, fmt.Errorf("压力%.1fkPa超出蒸气压表范围", P)
+}
+
+// buildStepTree 由计算明细组装树形结构
+func buildStepTree(T, rho, P float64, s calcSteps) (stepTree, error) {
+	var t stepTree
+	t.Input.T, t.Input.Rho, t.Input.P = T, s.rho, P
+	t.Concentration = treeConcentration{
+		Temps:          [2]float64{s.tLeft, s.tRight},
+		Densities:      [2]float64{roundHalfUp(s.rhoLeft, 4), roundHalfUp(s.rhoRight, 4)},
+		Concentrations: [2]float64{roundHalfUp(s.CLeft, 2), roundHalfUp(s.CRight, 2)},
+		C:              s.C,
+		Clamps:         s.clamps,
+	}
+	pressures, temps, err := vaporBracket(P)
+	if err != nil {
+		return t, err
+	}
+	t.Vapor = treeVapor{Pressures: pressures, Temps: temps, Tw: s.tw}
+	t.BPR = treeBPR{Atm: s.bprAtm, K: roundHalfUp(s.K, 4), BPR: s.bpr}
+	t.Tl = s.tl
+	t.Warnings = s.warnings
+	return t, nil
+}
+
+// writeStepTree 输出树形JSON（缩进，便于阅读）
+func writeStepTree(w io.Writer, T, rho, P float64, s calcSteps) error {
+	t, err := buildStepTree(T, rho, P, s)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}